@@ -0,0 +1,135 @@
+//go:build !race
+
+package onelog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The race detector instruments memory accesses and makes code allocate,
+// so allocation tests only run without it.
+func TestZeroAllocs(t *testing.T) {
+	ts := time.Date(2018, 12, 20, 9, 31, 23, 0, time.UTC)
+	_, ipNet, _ := net.ParseCIDR("10.0.0.0/8")
+	mac, _ := net.ParseMAC("00:00:5e:00:53:01")
+	t.Run("typed-fields", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO)
+		ip := net.ParseIP("2001:db8::1")
+		b := []byte("hello")
+		allocs := testing.AllocsPerRun(100, func() {
+			logger.InfoWith("hello").
+				Uint("uint", 1).
+				Int8("int8", -3).
+				Float32("float32", 1.5).
+				Time("time", ts, time.RFC3339).
+				DurationMs("durationMs", time.Second).
+				DurationString("durationString", 90500*time.Millisecond).
+				Bytes("bytes", b).
+				Hex("hex", b).
+				IP("ip", ip).
+				IPNet("ipNet", ipNet).
+				HardwareAddr("mac", mac).
+				RawJSON("raw", b).
+				Write()
+		})
+		assert.Equal(t, 0.0, allocs, "typed fields should not allocate")
+	})
+	t.Run("slices", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO)
+		strs := []string{"a", "b"}
+		ints := []int{1, 2}
+		times := []time.Time{ts}
+		allocs := testing.AllocsPerRun(100, func() {
+			logger.InfoWith("hello").
+				Strings("strings", strs).
+				Ints("ints", ints).
+				Times("times", times, time.RFC3339).
+				Write()
+		})
+		assert.Equal(t, 0.0, allocs, "slice fields should not allocate")
+	})
+	t.Run("array-func", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO)
+		allocs := testing.AllocsPerRun(100, func() {
+			logger.InfoWithFields("hello", func(e Entry) {
+				e.ArrayFunc("arr", func(a ArrayEntry) {
+					a.String("foo").Int(1).ObjectFunc(func(e Entry) {
+						e.Int("foo", 1)
+					})
+				})
+			})
+		})
+		assert.Equal(t, 0.0, allocs, "ArrayFunc should not allocate")
+	})
+	t.Run("any", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO)
+		var i interface{} = 10
+		var f interface{} = 1.5
+		var s interface{} = []string{"a"}
+		allocs := testing.AllocsPerRun(100, func() {
+			logger.InfoWith("hello").Any("int", i).Any("float", f).Any("strings", s).Write()
+		})
+		assert.Equal(t, 0.0, allocs, "fast paths should not allocate")
+	})
+	t.Run("dict", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO)
+		allocs := testing.AllocsPerRun(100, func() {
+			logger.InfoWith("hello").
+				Dict("user", logger.Dict().String("id", "123").Dict("address", logger.Dict().String("city", "Paris"))).
+				Write()
+		})
+		assert.Equal(t, 0.0, allocs, "Dict should not allocate")
+	})
+	t.Run("slog-handler", func(t *testing.T) {
+		w := newWriter()
+		h := NewSlogHandler(New(w, ALL)).WithAttrs([]slog.Attr{slog.String("service", "api")})
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, "hello", 0)
+		r.AddAttrs(slog.String("foo", "bar"), slog.Int("n", 1))
+		ctx := context.Background()
+		allocs := testing.AllocsPerRun(100, func() {
+			h.Handle(ctx, r)
+		})
+		assert.Equal(t, 0.0, allocs, "Handle should not allocate")
+	})
+	t.Run("sampler-dropped", func(t *testing.T) {
+		logger := New(&bytes.Buffer{}, ALL).Sampler(NewSampler(time.Hour, 1, 0))
+		logger.Info("message")
+		allocs := testing.AllocsPerRun(100, func() {
+			logger.InfoWithFields("message", func(e Entry) {
+				e.String("foo", "bar")
+			})
+			logger.InfoWith("message").String("foo", "bar").Write()
+		})
+		assert.Equal(t, float64(0), allocs, "dropped entries should not allocate")
+	})
+	t.Run("rate-limiter-suppressed", func(t *testing.T) {
+		logger := New(&bytes.Buffer{}, ALL).RateLimiter(NewRateLimiter().Limit(INFO, 0, 0))
+		allocs := testing.AllocsPerRun(100, func() {
+			logger.InfoWithFields("message", func(e Entry) {
+				e.String("foo", "bar")
+			})
+		})
+		assert.Equal(t, float64(0), allocs, "suppressed entries should not allocate")
+	})
+	t.Run("ctx-methods", func(t *testing.T) {
+		logger := New(newWriter(), INFO).Extractors(ContextValue(requestIDKey{}, "request_id"))
+		ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+		allocs := testing.AllocsPerRun(100, func() {
+			logger.InfoCtx(ctx, "hello")
+			logger.InfoWithCtx(ctx, "hello").String("foo", "bar").Write()
+		})
+		assert.Equal(t, 0.0, allocs, "Ctx logging methods should not allocate")
+	})
+}
@@ -0,0 +1,152 @@
+package onelog
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/francoispqt/gojay"
+)
+
+var jsonEncoderPool = sync.Pool{
+	New: func() interface{} {
+		e := &jsonEncoder{}
+		e.enc = json.NewEncoder(&e.buf)
+		e.enc.SetEscapeHTML(false)
+		return e
+	},
+}
+
+// jsonEncoder encodes values with encoding/json, it is used by Any for values without fast path.
+type jsonEncoder struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+// Any adds a value of any type to the log entry.
+//
+// Primitives, slices of primitives, time.Time, time.Duration, []byte (as base64), errors,
+// gojay marshalers, json.Marshaler, encoding.TextMarshaler and fmt.Stringer
+// are encoded without reflection, in that order of precedence for values implementing several of them.
+// Other values are encoded with encoding/json, honoring json tags.
+// Values which cannot be encoded are written as strings formatted with fmt.
+func (e Entry) Any(k string, v interface{}) Entry {
+	if s, ok := v.(string); ok {
+		e.enc.StringKey(k, e.stringValue(k, s))
+		return e
+	}
+	if e.redacts(k) {
+		e.redactAny(k, v)
+		return e
+	}
+	switch v := v.(type) {
+	case nil:
+		e.enc.AddNullKey(k)
+	case bool:
+		e.enc.BoolKey(k, v)
+	case int:
+		e.enc.IntKey(k, v)
+	case int8:
+		e.enc.Int8Key(k, v)
+	case int16:
+		e.enc.Int16Key(k, v)
+	case int32:
+		e.enc.Int32Key(k, v)
+	case int64:
+		e.enc.Int64Key(k, v)
+	case uint:
+		e.enc.Uint64Key(k, uint64(v))
+	case uint8:
+		e.enc.Uint8Key(k, v)
+	case uint16:
+		e.enc.Uint16Key(k, v)
+	case uint32:
+		e.enc.Uint32Key(k, v)
+	case uint64:
+		e.enc.Uint64Key(k, v)
+	case float32:
+		e.enc.Float32Key(k, v)
+	case float64:
+		e.enc.FloatKey(k, v)
+	case time.Time:
+		e.Time(k, v, time.RFC3339Nano)
+	case time.Duration:
+		e.Duration(k, v)
+	case []byte:
+		e.Bytes(k, v)
+	case []string:
+		e.Strings(k, v)
+	case []int:
+		e.Ints(k, v)
+	case []int64:
+		e.Int64s(k, v)
+	case []float64:
+		e.Floats(k, v)
+	case []bool:
+		e.Bools(k, v)
+	case []error:
+		e.Errs(k, v)
+	case []time.Time:
+		e.Times(k, v, time.RFC3339Nano)
+	case []time.Duration:
+		e.Durations(k, v)
+	default:
+		e.anyValue(k, v)
+	}
+	return e
+}
+
+// anyValue adds a value implementing one of the interfaces supported by Any to the log entry,
+// or encoded with encoding/json. Nil pointers are written as null as their methods would dereference them.
+func (e Entry) anyValue(k string, v interface{}) {
+	if isNilPointer(v) {
+		e.enc.AddNullKey(k)
+		return
+	}
+	switch v := v.(type) {
+	case gojay.MarshalerJSONObject:
+		e.enc.ObjectKey(k, v)
+	case gojay.MarshalerJSONArray:
+		e.enc.ArrayKey(k, v)
+	case json.Marshaler:
+		e.anyJSON(k, v)
+	case error:
+		e.errKey(k, v)
+	case encoding.TextMarshaler:
+		b, err := v.MarshalText()
+		if err != nil {
+			e.enc.StringKey(k, e.l.limitString(fmt.Sprintf("%+v", v)))
+			return
+		}
+		e.enc.StringKey(k, e.stringValue(k, string(b)))
+	case fmt.Stringer:
+		e.enc.StringKey(k, e.stringValue(k, v.String()))
+	default:
+		e.anyJSON(k, v)
+	}
+}
+
+// anyJSON adds v encoded with encoding/json to the log entry,
+// the output of json.Marshaler implementations is validated and compacted.
+func (e Entry) anyJSON(k string, v interface{}) {
+	j := jsonEncoderPool.Get().(*jsonEncoder)
+	j.buf.Reset()
+	if err := j.enc.Encode(v); err != nil {
+		e.enc.StringKey(k, e.l.limitString(fmt.Sprintf("%+v", v)))
+	} else {
+		e.RawJSON(k, bytes.TrimRight(j.buf.Bytes(), "\n"))
+	}
+	jsonEncoderPool.Put(j)
+}
+
+// Any adds a value of any type to the log entry, see Entry.Any.
+func (e ChainEntry) Any(k string, v interface{}) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Any(k, v)
+	return e
+}
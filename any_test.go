@@ -0,0 +1,120 @@
+package onelog
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type anyStruct struct {
+	Name    string            `json:"name"`
+	Age     int               `json:"age,omitempty"`
+	Secret  string            `json:"-"`
+	Tags    map[string]string `json:"tags"`
+	private int
+}
+
+type anyMarshaler struct{}
+
+func (anyMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte("{\n  \"foo\": \"<bar>\"\n}"), nil
+}
+
+type anyBadMarshaler struct{}
+
+func (anyBadMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"foo":`), nil
+}
+
+func (anyBadMarshaler) String() string { return "bad" }
+
+func TestEntryAny(t *testing.T) {
+	ts := time.Date(2018, 12, 20, 9, 31, 23, 5, time.UTC)
+	n := 42
+	testCases := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"nil", nil, `null`},
+		{"string", "foo", `"foo"`},
+		{"bool", true, `true`},
+		{"int", -1, `-1`},
+		{"int8", int8(-8), `-8`},
+		{"int16", int16(-16), `-16`},
+		{"int32", int32(-32), `-32`},
+		{"int64", int64(-64), `-64`},
+		{"uint", uint(1), `1`},
+		{"uint8", uint8(8), `8`},
+		{"uint16", uint16(16), `16`},
+		{"uint32", uint32(32), `32`},
+		{"uint64", uint64(64), `64`},
+		{"float32", float32(1.5), `1.5`},
+		{"float64", 10.1, `10.1`},
+		{"time", ts, `"2018-12-20T09:31:23.000000005Z"`},
+		{"duration", time.Second, `1000000000`},
+		{"bytes", []byte("hello"), `"aGVsbG8="`},
+		{"strings", []string{"a", "b"}, `["a","b"]`},
+		{"ints", []int{1, 2}, `[1,2]`},
+		{"int64s", []int64{1, 2}, `[1,2]`},
+		{"floats", []float64{1.5}, `[1.5]`},
+		{"bools", []bool{true}, `[true]`},
+		{"errs", []error{errors.New("boom")}, `["boom"]`},
+		{"times", []time.Time{ts}, `["2018-12-20T09:31:23.000000005Z"]`},
+		{"durations", []time.Duration{time.Millisecond}, `[1000000]`},
+		{"gojay-object", &TestObj{"bar"}, `{"foo":"bar"}`},
+		{"gojay-array", TestObjArr{&TestObj{"bar"}}, `[{"foo":"bar"}]`},
+		{"json-marshaler", anyMarshaler{}, `{"foo":"<bar>"}`},
+		{"invalid-json-marshaler", anyBadMarshaler{}, `"bad"`},
+		{"error", errors.New("my printer is on fire"), `"my printer is on fire"`},
+		{"text-marshaler", net.IPv4(127, 0, 0, 1), `"127.0.0.1"`},
+		{"stringer", testStringer{}, `"stringer"`},
+		{"struct", anyStruct{Name: "foo", Secret: "s", Tags: map[string]string{"b": "2", "a": "1"}}, `{"name":"foo","tags":{"a":"1","b":"2"}}`},
+		{"pointer", &n, `42`},
+		{"nil-pointer", (*anyStruct)(nil), `null`},
+		{"nil-pointer-error", (*url.Error)(nil), `null`},
+		{"nil-pointer-text-marshaler", (*time.Time)(nil), `null`},
+		{"nil-pointer-stringer", (*url.URL)(nil), `null`},
+		{"map", map[string]interface{}{"foo": []interface{}{1, "<a>"}}, `{"foo":[1,"<a>"]}`},
+		{"unsupported", make(chan int), `"0x`},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			expected := `{"level":"info","message":"hello","value":` + testCase.expected
+			w := newWriter()
+			logger := New(w, INFO)
+			logger.InfoWithFields("hello", func(e Entry) {
+				e.Any("value", testCase.value)
+			})
+			if testCase.name == "unsupported" {
+				assert.Contains(t, string(w.b), expected, "bytes written to the writer dont equal expected result")
+				return
+			}
+			assert.Equal(t, expected+"}\n", string(w.b), "bytes written to the writer dont equal expected result")
+
+			w = newWriter()
+			logger = New(w, INFO)
+			logger.InfoWith("hello").Any("value", testCase.value).Write()
+			assert.Equal(t, expected+"}\n", string(w.b), "bytes written to the writer dont equal expected result")
+		})
+	}
+	t.Run("redacted", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO).Redactor(NewRedactor().Keys("user").Detect(Email))
+		logger.InfoWith("hello").
+			Any("user", anyStruct{Name: "foo"}).
+			Any("email", errors.New("foo@example.com")).
+			Write()
+		assert.Equal(t, `{"level":"info","message":"hello","user":"[REDACTED]","email":"[REDACTED]"}`+"\n", string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("disabled", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, DEBUG)
+		logger.InfoWith("hello").Any("value", anyStruct{}).Write()
+		assert.Equal(t, ``, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+}
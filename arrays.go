@@ -0,0 +1,296 @@
+package onelog
+
+import (
+	"time"
+)
+
+// ArrayEntry is the structure used to add elements to an array built with ArrayFunc.
+type ArrayEntry struct {
+	e Entry
+	k string
+}
+
+// Strings adds a slice of strings to the log entry.
+func (e Entry) Strings(k string, v []string) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.key(k)
+	e.enc.AppendByte('[')
+	for _, s := range v {
+		e.enc.AddString(e.stringValue(k, s))
+	}
+	e.enc.AppendByte(']')
+	return e
+}
+
+// Ints adds a slice of ints to the log entry.
+func (e Entry) Ints(k string, v []int) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.key(k)
+	e.enc.AppendByte('[')
+	for _, i := range v {
+		e.enc.AddInt(i)
+	}
+	e.enc.AppendByte(']')
+	return e
+}
+
+// Int64s adds a slice of int64s to the log entry.
+func (e Entry) Int64s(k string, v []int64) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.key(k)
+	e.enc.AppendByte('[')
+	for _, i := range v {
+		e.enc.AddInt64(i)
+	}
+	e.enc.AppendByte(']')
+	return e
+}
+
+// Floats adds a slice of float64s to the log entry.
+func (e Entry) Floats(k string, v []float64) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.key(k)
+	e.enc.AppendByte('[')
+	for _, f := range v {
+		e.enc.AddFloat64(f)
+	}
+	e.enc.AppendByte(']')
+	return e
+}
+
+// Bools adds a slice of bools to the log entry.
+func (e Entry) Bools(k string, v []bool) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.key(k)
+	e.enc.AppendByte('[')
+	for _, b := range v {
+		e.enc.AddBool(b)
+	}
+	e.enc.AppendByte(']')
+	return e
+}
+
+// Errs adds a slice of errors to the log entry, nil errors are encoded as null.
+func (e Entry) Errs(k string, v []error) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.key(k)
+	e.enc.AppendByte('[')
+	for _, err := range v {
+		if err == nil {
+			e.enc.AddNull()
+			continue
+		}
+		e.errElem(k, err)
+	}
+	e.enc.AppendByte(']')
+	return e
+}
+
+// Times adds a slice of times formatted with the given layout to the log entry.
+func (e Entry) Times(k string, v []time.Time, format string) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.key(k)
+	e.enc.AppendByte('[')
+	for i := range v {
+		e.enc.AddTime(&v[i], format)
+	}
+	e.enc.AppendByte(']')
+	return e
+}
+
+// Durations adds a slice of durations as integer numbers of nanoseconds to the log entry.
+func (e Entry) Durations(k string, v []time.Duration) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.key(k)
+	e.enc.AppendByte('[')
+	for _, d := range v {
+		e.enc.AddInt64(int64(d))
+	}
+	e.enc.AppendByte(']')
+	return e
+}
+
+// ArrayFunc adds an array to the log entry by calling a function.
+func (e Entry) ArrayFunc(k string, v func(ArrayEntry)) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.key(k)
+	e.enc.AppendByte('[')
+	v(ArrayEntry{e: e, k: k})
+	e.enc.AppendByte(']')
+	return e
+}
+
+// Strings adds a slice of strings to the log entry.
+func (e ChainEntry) Strings(k string, v []string) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Strings(k, v)
+	return e
+}
+
+// Ints adds a slice of ints to the log entry.
+func (e ChainEntry) Ints(k string, v []int) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Ints(k, v)
+	return e
+}
+
+// Int64s adds a slice of int64s to the log entry.
+func (e ChainEntry) Int64s(k string, v []int64) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Int64s(k, v)
+	return e
+}
+
+// Floats adds a slice of float64s to the log entry.
+func (e ChainEntry) Floats(k string, v []float64) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Floats(k, v)
+	return e
+}
+
+// Bools adds a slice of bools to the log entry.
+func (e ChainEntry) Bools(k string, v []bool) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Bools(k, v)
+	return e
+}
+
+// Errs adds a slice of errors to the log entry, nil errors are encoded as null.
+func (e ChainEntry) Errs(k string, v []error) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Errs(k, v)
+	return e
+}
+
+// Times adds a slice of times formatted with the given layout to the log entry.
+func (e ChainEntry) Times(k string, v []time.Time, format string) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Times(k, v, format)
+	return e
+}
+
+// Durations adds a slice of durations as integer numbers of nanoseconds to the log entry.
+func (e ChainEntry) Durations(k string, v []time.Duration) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Durations(k, v)
+	return e
+}
+
+// ArrayFunc adds an array to the log entry by calling a function.
+func (e ChainEntry) ArrayFunc(k string, v func(ArrayEntry)) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.ArrayFunc(k, v)
+	return e
+}
+
+// String adds a string to the array.
+func (a ArrayEntry) String(v string) ArrayEntry {
+	a.e.enc.AddString(a.e.stringValue(a.k, v))
+	return a
+}
+
+// Int adds an int to the array.
+func (a ArrayEntry) Int(v int) ArrayEntry {
+	a.e.enc.AddInt(v)
+	return a
+}
+
+// Int64 adds an int64 to the array.
+func (a ArrayEntry) Int64(v int64) ArrayEntry {
+	a.e.enc.AddInt64(v)
+	return a
+}
+
+// Float adds a float64 to the array.
+func (a ArrayEntry) Float(v float64) ArrayEntry {
+	a.e.enc.AddFloat64(v)
+	return a
+}
+
+// Bool adds a bool to the array.
+func (a ArrayEntry) Bool(v bool) ArrayEntry {
+	a.e.enc.AddBool(v)
+	return a
+}
+
+// Err adds an error to the array, or null if v is nil.
+func (a ArrayEntry) Err(v error) ArrayEntry {
+	if v == nil {
+		a.e.enc.AddNull()
+		return a
+	}
+	a.e.errElem(a.k, v)
+	return a
+}
+
+// Time adds a time formatted with the given layout to the array.
+func (a ArrayEntry) Time(v time.Time, format string) ArrayEntry {
+	a.e.enc.AddTime(&v, format)
+	return a
+}
+
+// Duration adds a duration as an integer number of nanoseconds to the array.
+func (a ArrayEntry) Duration(v time.Duration) ArrayEntry {
+	a.e.enc.AddInt64(int64(v))
+	return a
+}
+
+// Null adds null to the array.
+func (a ArrayEntry) Null() ArrayEntry {
+	a.e.enc.AddNull()
+	return a
+}
+
+// ObjectFunc adds an object to the array by calling a function.
+func (a ArrayEntry) ObjectFunc(v func(Entry)) ArrayEntry {
+	a.e.elem()
+	a.e.enc.AppendByte('{')
+	v(a.e)
+	a.e.enc.AppendByte('}')
+	return a
+}
+
+// ArrayFunc adds a nested array to the array by calling a function.
+func (a ArrayEntry) ArrayFunc(v func(ArrayEntry)) ArrayEntry {
+	a.e.elem()
+	a.e.enc.AppendByte('[')
+	v(a)
+	a.e.enc.AppendByte(']')
+	return a
+}
@@ -0,0 +1,105 @@
+package onelog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntrySlices(t *testing.T) {
+	ts := time.Date(2018, 12, 20, 9, 31, 23, 0, time.UTC)
+	json := `{"level":"info","message":"hello","strings":["a","b\"c"],"ints":[1,-2],"int64s":[3],` +
+		`"floats":[1.5,2],"bools":[true,false],"errs":["boom",null],"times":["2018-12-20T09:31:23Z"],` +
+		`"durations":[1000000],"empty":[]}` + "\n"
+	t.Run("entry", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO)
+		logger.InfoWithFields("hello", func(e Entry) {
+			e.Strings("strings", []string{"a", `b"c`}).
+				Ints("ints", []int{1, -2}).
+				Int64s("int64s", []int64{3}).
+				Floats("floats", []float64{1.5, 2}).
+				Bools("bools", []bool{true, false}).
+				Errs("errs", []error{errors.New("boom"), nil}).
+				Times("times", []time.Time{ts}, time.RFC3339).
+				Durations("durations", []time.Duration{time.Millisecond}).
+				Strings("empty", nil)
+		})
+		assert.Equal(t, json, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("chain-entry", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO)
+		logger.InfoWith("hello").
+			Strings("strings", []string{"a", `b"c`}).
+			Ints("ints", []int{1, -2}).
+			Int64s("int64s", []int64{3}).
+			Floats("floats", []float64{1.5, 2}).
+			Bools("bools", []bool{true, false}).
+			Errs("errs", []error{errors.New("boom"), nil}).
+			Times("times", []time.Time{ts}, time.RFC3339).
+			Durations("durations", []time.Duration{time.Millisecond}).
+			Strings("empty", nil).
+			Write()
+		assert.Equal(t, json, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("chain-entry-disabled", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, DEBUG)
+		logger.InfoWith("hello").Strings("strings", []string{"a"}).Ints("ints", []int{1}).Write()
+		assert.Equal(t, ``, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("redacted", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO).Redactor(NewRedactor().Keys("ids").Detect(Email))
+		logger.InfoWith("hello").
+			Ints("ids", []int{1, 2}).
+			Strings("to", []string{"a", "foo@example.com"}).
+			Write()
+		assert.Equal(t, `{"level":"info","message":"hello","ids":"[REDACTED]","to":["a","[REDACTED]"]}`+"\n", string(w.b), "bytes written to the writer dont equal expected result")
+	})
+}
+
+func TestEntryArrayFunc(t *testing.T) {
+	ts := time.Date(2018, 12, 20, 9, 31, 23, 0, time.UTC)
+	t.Run("heterogeneous", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO)
+		logger.InfoWith("hello").
+			ArrayFunc("arr", func(a ArrayEntry) {
+				a.String("foo").
+					Int(1).
+					Int64(2).
+					Float(1.5).
+					Bool(true).
+					Err(errors.New("boom")).
+					Err(nil).
+					Time(ts, time.RFC3339).
+					Duration(time.Second).
+					Null().
+					ObjectFunc(func(e Entry) {
+						e.String("foo", "bar").Int("baz", 1)
+					}).
+					ArrayFunc(func(a ArrayEntry) {
+						a.Int(1).Int(2)
+					})
+			}).
+			Int("after", 1).
+			Write()
+		json := `{"level":"info","message":"hello","arr":["foo",1,2,1.5,true,"boom",null,` +
+			`"2018-12-20T09:31:23Z",1000000000,null,{"foo":"bar","baz":1},[1,2]],"after":1}` + "\n"
+		assert.Equal(t, json, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("nested-first", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO)
+		logger.InfoWithFields("hello", func(e Entry) {
+			e.ArrayFunc("arr", func(a ArrayEntry) {
+				a.ObjectFunc(func(e Entry) {}).ArrayFunc(func(a ArrayEntry) {})
+			})
+		})
+		assert.Equal(t, `{"level":"info","message":"hello","arr":[{},[]]}`+"\n", string(w.b), "bytes written to the writer dont equal expected result")
+	})
+}
@@ -0,0 +1,220 @@
+package onelog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Kinds of issues reported by VerifyAudit.
+const (
+	AuditMalformed = "malformed"
+	AuditModified  = "modified"
+	AuditMissing   = "missing"
+	AuditReordered = "reordered"
+	AuditDuplicate = "duplicate"
+)
+
+var (
+	auditSeqKey = []byte(`,"seq":`)
+	auditMacKey = []byte(`,"mac":"`)
+)
+
+// AuditWriter is an io.Writer making a stream of entries tamper-evident.
+//
+// It adds to each entry a "seq" field holding its sequence number starting at 1
+// and a "mac" field holding the hex encoded HMAC-SHA256 of the previous entry's "mac" value
+// followed by the entry including its "seq" field but not its "mac" field.
+// Each call to Write must pass a single entry, as loggers do.
+type AuditWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	mac hash.Hash
+	seq uint64
+	sum []byte
+	buf []byte
+}
+
+// AuditIssue is an issue found in an audit log stream by VerifyAudit.
+type AuditIssue struct {
+	// Line is the line number of the entry in the stream, it is 0 for missing entries.
+	Line int
+	// Seq is the sequence number of the entry.
+	Seq uint64
+	// Kind is the kind of issue: AuditMalformed, AuditModified, AuditMissing, AuditReordered or AuditDuplicate.
+	Kind string
+}
+
+// NewAuditWriter returns an AuditWriter writing to w and signing entries with key.
+func NewAuditWriter(w io.Writer, key []byte) *AuditWriter {
+	return &AuditWriter{
+		w:   w,
+		mac: hmac.New(sha256.New, key),
+	}
+}
+
+// Audit wraps the logger's writer in an AuditWriter signing entries with key and returns the logger.
+// Loggers derived with With and WithContext share the same chain.
+// A deduper set with Dedup writes through the AuditWriter too.
+func (l *Logger) Audit(key []byte) *Logger {
+	l.w = NewAuditWriter(l.w, key)
+	if l.dedup != nil {
+		l.dedup.bind(l.w)
+	}
+	return l
+}
+
+// Write adds the sequence number and the MAC to the entry b and writes it.
+func (a *AuditWriter) Write(b []byte) (int, error) {
+	line := bytes.TrimRight(b, "\n")
+	if len(line) < 2 || line[len(line)-1] != '}' {
+		return a.w.Write(b)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.seq++
+	a.buf = append(a.buf[:0], line[:len(line)-1]...)
+	if len(line) > 2 {
+		a.buf = append(a.buf, auditSeqKey...)
+	} else {
+		a.buf = append(a.buf, auditSeqKey[1:]...)
+	}
+	a.buf = strconv.AppendUint(a.buf, a.seq, 10)
+	a.buf = append(a.buf, '}')
+	a.sum = auditSum(a.mac, a.sum, a.buf)
+	a.buf = a.buf[:len(a.buf)-1]
+	a.buf = append(a.buf, auditMacKey...)
+	a.buf = append(a.buf, a.sum...)
+	a.buf = append(a.buf, '"', '}', '\n')
+	if _, err := a.w.Write(a.buf); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// auditSum returns the hex encoded MAC of prev followed by body.
+func auditSum(mac hash.Hash, prev, body []byte) []byte {
+	mac.Reset()
+	mac.Write(prev)
+	mac.Write(body)
+	var sum [sha256.Size]byte
+	dst := make([]byte, hex.EncodedLen(sha256.Size))
+	hex.Encode(dst, mac.Sum(sum[:0]))
+	return dst
+}
+
+type auditLine struct {
+	line int
+	body []byte
+	sum  []byte
+}
+
+// VerifyAudit reads a stream written by an AuditWriter and reports
+// missing, reordered, duplicated, modified and malformed entries.
+// The stream must start at the first entry of the chain.
+func VerifyAudit(r io.Reader, key []byte) ([]AuditIssue, error) {
+	var issues []AuditIssue
+	mac := hmac.New(sha256.New, key)
+	sums := map[uint64][]byte{0: nil}
+	pending := map[uint64]auditLine{}
+	var maxSeq uint64
+
+	verify := func(seq uint64, l auditLine) {
+		if !hmac.Equal(auditSum(mac, sums[seq-1], l.body), l.sum) {
+			issues = append(issues, AuditIssue{Line: l.line, Seq: seq, Kind: AuditModified})
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	n := 0
+	for scanner.Scan() {
+		n++
+		seq, body, sum, ok := parseAuditLine(scanner.Bytes())
+		if !ok {
+			issues = append(issues, AuditIssue{Line: n, Kind: AuditMalformed})
+			continue
+		}
+		if _, seen := sums[seq]; seen {
+			issues = append(issues, AuditIssue{Line: n, Seq: seq, Kind: AuditDuplicate})
+			continue
+		}
+		if seq < maxSeq {
+			issues = append(issues, AuditIssue{Line: n, Seq: seq, Kind: AuditReordered})
+		} else {
+			maxSeq = seq
+		}
+		l := auditLine{line: n, body: body, sum: sum}
+		sums[seq] = sum
+		if _, ok := sums[seq-1]; ok {
+			verify(seq, l)
+		} else {
+			pending[seq] = l
+		}
+		// verify entries which were waiting for this one.
+		for next := seq + 1; ; next++ {
+			l, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			verify(next, l)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return issues, err
+	}
+	for seq := uint64(1); seq < maxSeq; seq++ {
+		if _, ok := sums[seq]; !ok {
+			issues = append(issues, AuditIssue{Seq: seq, Kind: AuditMissing})
+		}
+	}
+	// missing entries are reported last.
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if (a.Line == 0) != (b.Line == 0) {
+			return b.Line == 0
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Seq < b.Seq
+	})
+	return issues, nil
+}
+
+// parseAuditLine splits a line into its sequence number, the signed body and the MAC.
+func parseAuditLine(line []byte) (uint64, []byte, []byte, bool) {
+	line = bytes.TrimSpace(line)
+	i := bytes.LastIndex(line, auditMacKey)
+	if i < 0 || !bytes.HasSuffix(line, []byte(`"}`)) {
+		return 0, nil, nil, false
+	}
+	sum := line[i+len(auditMacKey) : len(line)-2]
+	if len(sum) != hex.EncodedLen(sha256.Size) {
+		return 0, nil, nil, false
+	}
+	body := make([]byte, i+1)
+	copy(body, line[:i])
+	body[i] = '}'
+	var seq uint64
+	found := false
+	eachMember(body, func(k []byte, start, end int) bool {
+		if string(k) == "seq" {
+			v, err := strconv.ParseUint(string(body[start:end]), 10, 64)
+			seq, found = v, err == nil
+		}
+		return true
+	})
+	if !found || seq == 0 {
+		return 0, nil, nil, false
+	}
+	return seq, body, append([]byte(nil), sum...), true
+}
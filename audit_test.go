@@ -0,0 +1,94 @@
+package onelog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func auditLog(n int) []string {
+	w := &bytes.Buffer{}
+	logger := New(w, ALL).Audit([]byte("secret"))
+	ctxLogger := logger.WithContext("params")
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			logger.InfoWith("hello").Int("i", i).Write()
+			continue
+		}
+		ctxLogger.WarnWithFields("hello", func(e Entry) {
+			e.Int("i", i)
+		})
+	}
+	lines := strings.SplitAfter(w.String(), "\n")
+	return lines[:len(lines)-1]
+}
+
+func TestAudit(t *testing.T) {
+	t.Run("format", func(t *testing.T) {
+		lines := auditLog(2)
+		assert.Len(t, lines, 2, "2 entries should have been written")
+		assert.Regexp(t, `^\{"level":"info","message":"hello","i":0,"seq":1,"mac":"[0-9a-f]{64}"\}`+"\n$", lines[0], "first entry should be signed")
+		assert.Regexp(t, `^\{"level":"warn","message":"hello","params":\{"i":1\},"seq":2,"mac":"[0-9a-f]{64}"\}`+"\n$", lines[1], "second entry should be signed")
+	})
+	t.Run("valid", func(t *testing.T) {
+		lines := auditLog(5)
+		issues, err := VerifyAudit(strings.NewReader(strings.Join(lines, "")), []byte("secret"))
+		assert.Nil(t, err, "err should be nil")
+		assert.Len(t, issues, 0, "there should be no issue")
+	})
+	t.Run("wrong-key", func(t *testing.T) {
+		lines := auditLog(2)
+		issues, err := VerifyAudit(strings.NewReader(strings.Join(lines, "")), []byte("wrong"))
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, []AuditIssue{{1, 1, AuditModified}, {2, 2, AuditModified}}, issues, "all entries should be reported")
+	})
+	t.Run("modified", func(t *testing.T) {
+		lines := auditLog(3)
+		lines[1] = strings.Replace(lines[1], `"i":1`, `"i":2`, 1)
+		issues, err := VerifyAudit(strings.NewReader(strings.Join(lines, "")), []byte("secret"))
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, []AuditIssue{{2, 2, AuditModified}}, issues, "modified entry should be reported")
+	})
+	t.Run("missing", func(t *testing.T) {
+		lines := auditLog(5)
+		lines = append(lines[:1], lines[3:]...)
+		issues, err := VerifyAudit(strings.NewReader(strings.Join(lines, "")), []byte("secret"))
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, []AuditIssue{{0, 2, AuditMissing}, {0, 3, AuditMissing}}, issues, "missing entries should be reported")
+	})
+	t.Run("reordered-and-duplicate", func(t *testing.T) {
+		lines := auditLog(4)
+		lines[1], lines[2] = lines[2], lines[1]
+		lines = append(lines, lines[0])
+		issues, err := VerifyAudit(strings.NewReader(strings.Join(lines, "")), []byte("secret"))
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, []AuditIssue{{3, 2, AuditReordered}, {5, 1, AuditDuplicate}}, issues, "reordered and duplicate entries should be reported")
+	})
+	t.Run("malformed", func(t *testing.T) {
+		lines := auditLog(2)
+		lines = append(lines, "not json\n")
+		issues, err := VerifyAudit(strings.NewReader(strings.Join(lines, "")), []byte("secret"))
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, []AuditIssue{{3, 0, AuditMalformed}}, issues, "malformed line should be reported")
+	})
+	t.Run("with-dedup", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		d := NewDeduper(time.Hour)
+		logger := New(w, ALL).Dedup(d).Audit([]byte("secret"))
+		logger.Info("a")
+		logger.Info("a")
+		logger.Info("b")
+		d.Flush()
+		lines := strings.SplitAfter(w.String(), "\n")
+		assert.Len(t, lines, 4, "3 entries should have been written")
+		for _, line := range lines[:3] {
+			assert.Regexp(t, `,"seq":\d+,"mac":"[0-9a-f]{64}"\}`+"\n$", line, "entries written by the deduper should be signed")
+		}
+		issues, err := VerifyAudit(strings.NewReader(w.String()), []byte("secret"))
+		assert.Nil(t, err, "err should be nil")
+		assert.Len(t, issues, 0, "there should be no issue")
+	})
+}
@@ -1,6 +1,7 @@
 package benchmarks
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -108,3 +109,38 @@ func BenchmarkOnelog(b *testing.B) {
 		})
 	})
 }
+
+func BenchmarkAccumulatedFields(b *testing.B) {
+	for _, n := range []int{5, 10} {
+		fields := func(e onelog.Entry) {
+			for i := 0; i < n; i++ {
+				e.String(fieldKeys[i], "value")
+			}
+		}
+		b.Run(fmt.Sprintf("with-%d-fields", n), func(b *testing.B) {
+			logger := onelog.New(ioutil.Discard, onelog.ALL).With(fields)
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logger.Info("message")
+				}
+			})
+		})
+		b.Run(fmt.Sprintf("with-static-%d-fields", n), func(b *testing.B) {
+			logger := onelog.New(ioutil.Discard, onelog.ALL).WithStatic(fields)
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logger.Info("message")
+				}
+			})
+		})
+	}
+}
+
+var fieldKeys = []string{
+	"service", "version", "region", "zone", "host",
+	"pid", "environment", "cluster", "namespace", "pod",
+}
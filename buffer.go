@@ -0,0 +1,137 @@
+package onelog
+
+import "sync"
+
+// DefaultBufferSize is the maximum size in bytes of the entries held by a scope buffer created with WithBuffer.
+const DefaultBufferSize = 1 << 20
+
+// scopeBuffer holds entries in memory until an entry with a trigger level is written.
+type scopeBuffer struct {
+	mu        sync.Mutex
+	trigger   uint8
+	triggered bool
+	size      int
+	data      []byte
+	ends      []int
+	// dropped is the number of entries dropped because the buffer was full.
+	dropped uint64
+}
+
+// WithBuffer copies the current Logger and attaches a new scope buffer to it ("fingers crossed" logging).
+//
+// Entries with a level not in trigger are held in memory. When an entry with a level in trigger is logged,
+// or a FATAL entry which always triggers the buffer as the program exits after it,
+// held entries are written in order followed by the entry and the buffer is bypassed for the rest of the scope.
+// If no such entry is logged, held entries are discarded by calling Discard at the end of the scope.
+// Loggers derived from the returned logger with With and WithContext share the same scope.
+// At most DefaultBufferSize bytes of entries are held, see WithBufferSize.
+//
+// Example:
+//
+//	logger := parent.WithBuffer(onelog.ERROR)
+//	defer logger.Discard()
+func (l *Logger) WithBuffer(trigger uint8) *Logger {
+	return l.WithBufferSize(trigger, DefaultBufferSize)
+}
+
+// WithBufferSize is like WithBuffer but holds at most size bytes of entries.
+// When an entry does not fit, the oldest entries are dropped to keep the most recent context,
+// and a WARN entry with message "buffer overflow" and a "dropped" field counting them
+// is written before the held entries when they are flushed.
+func (l *Logger) WithBufferSize(trigger uint8, size int) *Logger {
+	nL := l.copy(l.contextName)
+	nL.buffer = &scopeBuffer{trigger: trigger, size: size}
+	return nL
+}
+
+// Flush writes the entries held by the logger's scope buffer.
+func (l *Logger) Flush() {
+	if l.buffer == nil {
+		return
+	}
+	l.buffer.mu.Lock()
+	l.buffer.flush(l)
+	l.buffer.mu.Unlock()
+}
+
+// Discard drops the entries held by the logger's scope buffer.
+func (l *Logger) Discard() {
+	if l.buffer == nil {
+		return
+	}
+	l.buffer.mu.Lock()
+	l.buffer.data = l.buffer.data[:0]
+	l.buffer.ends = l.buffer.ends[:0]
+	l.buffer.dropped = 0
+	l.buffer.mu.Unlock()
+}
+
+// hold stores a copy of the entry and reports whether it has been held.
+// If the level is a trigger level or FATAL, held entries are written and the entry is not held.
+func (b *scopeBuffer) hold(l *Logger, level uint8, entry []byte) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.triggered {
+		return false
+	}
+	if level&(b.trigger|FATAL) != 0 {
+		b.triggered = true
+		b.flush(l)
+		return false
+	}
+	if len(b.data)+len(entry) > b.size {
+		b.makeRoom(len(entry))
+		if len(entry) > b.size {
+			b.dropped++
+			return true
+		}
+	}
+	b.data = append(b.data, entry...)
+	b.ends = append(b.ends, len(b.data))
+	return true
+}
+
+// makeRoom drops the oldest entries so n bytes fit, freeing a quarter of the buffer at least
+// so entries are not moved on every write once the buffer is full. b.mu must be held.
+func (b *scopeBuffer) makeRoom(n int) {
+	max := b.size - n
+	if quarter := b.size * 3 / 4; quarter < max {
+		max = quarter
+	}
+	i := 0
+	for i < len(b.ends) && len(b.data)-b.ends[i] > max {
+		i++
+	}
+	if i == len(b.ends) {
+		b.dropped += uint64(len(b.ends))
+		b.data = b.data[:0]
+		b.ends = b.ends[:0]
+		return
+	}
+	cut := b.ends[i]
+	b.dropped += uint64(i + 1)
+	b.data = b.data[:copy(b.data, b.data[cut:])]
+	n = copy(b.ends, b.ends[i+1:])
+	for j := range b.ends[:n] {
+		b.ends[j] -= cut
+	}
+	b.ends = b.ends[:n]
+}
+
+// flush writes held entries, b.mu must be held.
+func (b *scopeBuffer) flush(l *Logger) {
+	if b.dropped > 0 {
+		dropped := b.dropped
+		l.writeInternal(WARN, "buffer overflow", func(e Entry) {
+			e.enc.Uint64Key("dropped", dropped)
+		})
+		b.dropped = 0
+	}
+	start := 0
+	for _, end := range b.ends {
+		l.output(b.data[start:end])
+		start = end
+	}
+	b.data = b.data[:0]
+	b.ends = b.ends[:0]
+}
@@ -0,0 +1,138 @@
+package onelog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithBuffer(t *testing.T) {
+	t.Run("discarded-without-trigger", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).WithBuffer(ERROR | FATAL)
+		logger.Debug("debug")
+		logger.InfoWithFields("info", func(e Entry) {
+			e.String("foo", "bar")
+		})
+		logger.WarnWith("warn").Int("i", 1).Write()
+		assert.Equal(t, ``, w.String(), "nothing should have been written")
+		logger.Discard()
+		logger.Flush()
+		assert.Equal(t, ``, w.String(), "nothing should have been written")
+	})
+	t.Run("flushed-on-trigger", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).WithBuffer(ERROR | FATAL)
+		logger.Debug("debug")
+		logger.With(func(e Entry) {
+			e.String("userID", "123")
+		}).InfoWithFields("info", func(e Entry) {
+			e.String("foo", "bar")
+		})
+		logger.WithContext("params").ErrorWith("error").Int("i", 1).Write()
+		logger.Info("after")
+		assert.Equal(
+			t,
+			`{"level":"debug","message":"debug"}`+"\n"+
+				`{"level":"info","message":"info","userID":"123","foo":"bar"}`+"\n"+
+				`{"level":"error","message":"error","params":{"i":1}}`+"\n"+
+				`{"level":"info","message":"after"}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+	})
+	t.Run("scopes-are-independent", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		parent := New(w, ALL)
+		scope1 := parent.WithBuffer(ERROR)
+		scope2 := parent.WithBuffer(ERROR)
+		scope1.Info("scope1")
+		scope2.Info("scope2")
+		parent.Info("parent")
+		scope2.Error("scope2")
+		scope1.Discard()
+		assert.Equal(
+			t,
+			`{"level":"info","message":"parent"}`+"\n"+
+				`{"level":"info","message":"scope2"}`+"\n"+
+				`{"level":"error","message":"scope2"}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+	})
+	t.Run("flushed-on-fatal", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).WithBuffer(ERROR)
+		exited := 0
+		logger.ExitFn = func(int) { exited++ }
+		logger.Info("info")
+		logger.Fatal("fatal")
+		assert.Equal(
+			t,
+			`{"level":"info","message":"info"}`+"\n"+
+				`{"level":"fatal","message":"fatal"}`+"\n",
+			w.String(),
+			"fatal entries should flush the buffer whatever the trigger",
+		)
+		assert.Equal(t, 1, exited, "logger should exit")
+	})
+	t.Run("explicit-flush", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).WithBuffer(ERROR)
+		logger.Info("info")
+		logger.Flush()
+		assert.Equal(t, `{"level":"info","message":"info"}`+"\n", w.String(), "bytes written to the writer dont equal expected result")
+	})
+}
+
+func TestWithBufferSize(t *testing.T) {
+	t.Run("oldest-dropped", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		// each entry is 43 bytes long
+		logger := New(w, ALL).WithBufferSize(ERROR, 100)
+		for i := 0; i < 4; i++ {
+			logger.InfoWith("message").Int("i", i).Write()
+		}
+		logger.Error("error")
+		assert.Equal(
+			t,
+			`{"level":"warn","message":"buffer overflow","dropped":2}`+"\n"+
+				`{"level":"info","message":"message","i":2}`+"\n"+
+				`{"level":"info","message":"message","i":3}`+"\n"+
+				`{"level":"error","message":"error"}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+	})
+	t.Run("entry-larger-than-buffer", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).WithBufferSize(ERROR, 10)
+		logger.Info("message")
+		logger.Flush()
+		assert.Equal(t, `{"level":"warn","message":"buffer overflow","dropped":1}`+"\n", w.String(), "entry should have been dropped")
+	})
+	t.Run("discard-resets-dropped", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).WithBufferSize(ERROR, 10)
+		logger.Info("message")
+		logger.Discard()
+		logger.Flush()
+		assert.Equal(t, ``, w.String(), "nothing should have been written")
+	})
+}
+
+func TestWithBufferInternalEntries(t *testing.T) {
+	start := time.Now()
+	defer setNow(start)()
+	w := &bytes.Buffer{}
+	logger := New(w, ALL).Sampler(NewSampler(time.Second, 1, 0).Summary(true))
+	scope := logger.WithBuffer(ERROR)
+	scope.Info("message")
+	scope.Info("message")
+	now = func() time.Time { return start.Add(2 * time.Second) }
+	scope.Info("message")
+	scope.Discard()
+	assert.Equal(t, `{"level":"info","message":"sampled","dropped":1}`+"\n", w.String(), "summary should not be held by the scope buffer")
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/francoispqt/onelog"
+)
+
+// severities are the onelog levels from the least to the most severe.
+var severities = []uint8{onelog.DEBUG, onelog.INFO, onelog.WARN, onelog.ERROR, onelog.FATAL}
+
+// filter keeps the entries matching all its conditions.
+type filter struct {
+	// levels is the mask of levels kept, 0 keeps all levels.
+	levels  uint8
+	exprs   []expr
+	timeKey string
+	since   time.Time
+	until   time.Time
+}
+
+// active reports whether the filter has any condition.
+func (f *filter) active() bool {
+	return f.levels != 0 || len(f.exprs) > 0 || !f.since.IsZero() || !f.until.IsZero()
+}
+
+func (f *filter) match(rec onelog.Record) bool {
+	if f.levels != 0 && rec.Level&f.levels == 0 {
+		return false
+	}
+	if !f.since.IsZero() || !f.until.IsZero() {
+		t, ok := recordTime(rec, f.timeKey)
+		if !ok || (!f.since.IsZero() && t.Before(f.since)) || (!f.until.IsZero() && !t.Before(f.until)) {
+			return false
+		}
+	}
+	for _, e := range f.exprs {
+		if !e.match(rec) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLevels parses a level list like "warn", "warn+" or "info,error" into a mask.
+func parseLevels(s string) (uint8, error) {
+	var mask uint8
+	if s == "" {
+		return 0, nil
+	}
+	for _, name := range strings.Split(s, ",") {
+		above := strings.HasSuffix(name, "+")
+		name = strings.TrimSuffix(name, "+")
+		found := false
+		for i, l := range severities {
+			if !strings.EqualFold(onelog.Levels[l], name) {
+				continue
+			}
+			found = true
+			mask |= l
+			if above {
+				for _, h := range severities[i+1:] {
+					mask |= h
+				}
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown level %q", name)
+		}
+	}
+	return mask, nil
+}
+
+// parseTime parses an RFC 3339 time or a duration before now. The empty string is the zero time.
+func parseTime(s string, now func() time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339 or a duration", s)
+	}
+	return t, nil
+}
+
+// recordTime returns the time of the entry, an RFC 3339 string or a unix timestamp
+// in seconds, milliseconds, microseconds or nanoseconds guessed from its magnitude.
+func recordTime(rec onelog.Record, key string) (time.Time, bool) {
+	v, ok := lookup(rec, key)
+	if !ok {
+		return time.Time{}, false
+	}
+	switch v := v.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	case float64:
+		switch {
+		case v > 1e17:
+			return time.Unix(0, int64(v)), true
+		case v > 1e14:
+			return time.UnixMicro(int64(v)), true
+		case v > 1e11:
+			return time.UnixMilli(int64(v)), true
+		default:
+			return time.Unix(0, int64(v*1e9)), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// lookup returns the decoded value of a field. Dotted paths like "user.id" select fields
+// of nested objects, the first part being a field or the context namespace.
+func lookup(rec onelog.Record, path string) (interface{}, bool) {
+	if f, ok := rec.Field(path); ok {
+		return decode(f)
+	}
+	parts := strings.Split(path, ".")
+	var v interface{}
+	if f, ok := rec.Field(parts[0]); ok {
+		if v, ok = decode(f); !ok {
+			return nil, false
+		}
+	} else if parts[0] == rec.ContextName && rec.ContextName != "" {
+		ctx := map[string]interface{}{}
+		for _, f := range rec.Context {
+			ctx[f.Key], _ = decode(f)
+		}
+		v = ctx
+	} else {
+		return nil, false
+	}
+	for _, p := range parts[1:] {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[p]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func decode(f onelog.Field) (interface{}, bool) {
+	var v interface{}
+	err := json.Unmarshal(f.Value, &v)
+	return v, err == nil
+}
+
+// expr is a field expression like "user.id=42" or "duration>500ms".
+type expr struct {
+	path  string
+	op    string
+	value string
+}
+
+var ops = []string{"!=", ">=", "<=", "=", ">", "<"}
+
+func parseExpr(s string) (expr, error) {
+	i := strings.IndexAny(s, "!=<>")
+	if i <= 0 {
+		return expr{}, fmt.Errorf("invalid expression %q, expected a field, an operator and a value", s)
+	}
+	for _, op := range ops {
+		if strings.HasPrefix(s[i:], op) {
+			return expr{path: s[:i], op: op, value: s[i+len(op):]}, nil
+		}
+	}
+	return expr{}, fmt.Errorf("invalid operator in expression %q", s)
+}
+
+// match compares the field with the value of the expression: as durations if the value is a duration,
+// as numbers if it is a number, and as strings otherwise. Entries without the field never match.
+//
+// Duration fields are strings like "1.5s", numbers of milliseconds if the key ends with "_ms",
+// and numbers of nanoseconds otherwise, as written by Entry.DurationString, DurationMs and Duration.
+func (e expr) match(rec onelog.Record) bool {
+	v, ok := lookup(rec, e.path)
+	if !ok {
+		return false
+	}
+	if _, err := strconv.ParseFloat(e.value, 64); err != nil {
+		if want, err := time.ParseDuration(e.value); err == nil {
+			got, ok := fieldDuration(e.path, v)
+			return ok && compare(e.op, compareFloats(float64(got), float64(want)))
+		}
+	}
+	if want, err := strconv.ParseFloat(e.value, 64); err == nil {
+		switch v := v.(type) {
+		case float64:
+			return compare(e.op, compareFloats(v, want))
+		case string:
+			if got, err := strconv.ParseFloat(v, 64); err == nil {
+				return compare(e.op, compareFloats(got, want))
+			}
+		}
+	}
+	var got string
+	switch v := v.(type) {
+	case string:
+		got = v
+	case nil:
+		got = "null"
+	default:
+		b, _ := json.Marshal(v)
+		got = string(b)
+	}
+	return compare(e.op, strings.Compare(got, e.value))
+}
+
+func fieldDuration(path string, v interface{}) (time.Duration, bool) {
+	switch v := v.(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		return d, err == nil
+	case float64:
+		if strings.HasSuffix(path, "_ms") {
+			return time.Duration(v * float64(time.Millisecond)), true
+		}
+		return time.Duration(v), true
+	}
+	return 0, false
+}
+
+// compare reports whether the result c of a comparison satisfies the operator.
+func compare(op string, c int) bool {
+	switch op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/francoispqt/onelog"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeRecord(t *testing.T, line string) onelog.Record {
+	rec, err := onelog.NewReader(nil).ContextNames("params").Decode([]byte(line))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+func TestParseLevels(t *testing.T) {
+	testCases := []struct {
+		in   string
+		mask uint8
+	}{
+		{"", 0},
+		{"warn", onelog.WARN},
+		{"warn+", onelog.WARN | onelog.ERROR | onelog.FATAL},
+		{"debug+", onelog.ALL},
+		{"info,ERROR", onelog.INFO | onelog.ERROR},
+	}
+	for _, testCase := range testCases {
+		mask, err := parseLevels(testCase.in)
+		assert.Nil(t, err, "err should be nil for %q", testCase.in)
+		assert.Equal(t, testCase.mask, mask, "mask of %q should be parsed", testCase.in)
+	}
+	_, err := parseLevels("verbose")
+	assert.EqualError(t, err, `unknown level "verbose"`, "unknown level should be an error")
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2018, 12, 20, 9, 31, 23, 0, time.UTC)
+	since, err := parseTime("15m", func() time.Time { return now })
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, now.Add(-15*time.Minute), since, "duration should be before now")
+	since, err = parseTime("2018-12-20T08:00:00Z", nil)
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, time.Date(2018, 12, 20, 8, 0, 0, 0, time.UTC), since, "RFC 3339 time should be parsed")
+	_, err = parseTime("yesterday", nil)
+	assert.NotNil(t, err, "err should not be nil")
+}
+
+func TestExpr(t *testing.T) {
+	rec := decodeRecord(t, `{"level":"info","message":"m","user":{"id":42,"name":"bob"},"status":"200","took":"1.5s","duration_ms":750.5,"elapsed":2000000000,"ok":true,"params":{"code":403}}`)
+	testCases := []struct {
+		expr  string
+		match bool
+	}{
+		{"user.id=42", true},
+		{"user.id!=42", false},
+		{"user.id>=42", true},
+		{"user.id<42", false},
+		{"user.name=bob", true},
+		{"user.name>alice", true},
+		{"status=200", true},
+		{"status>199", true},
+		{"took>1s", true},
+		{"took<=1s", false},
+		{"duration_ms>500ms", true},
+		{"duration_ms>1s", false},
+		{"elapsed=2s", true},
+		{"ok=true", true},
+		{"params.code=403", true},
+		{"missing=1", false},
+		{"user.missing!=1", false},
+	}
+	for _, testCase := range testCases {
+		e, err := parseExpr(testCase.expr)
+		assert.Nil(t, err, "err should be nil for %q", testCase.expr)
+		assert.Equal(t, testCase.match, e.match(rec), "match of %q should be %v", testCase.expr, testCase.match)
+	}
+	_, err := parseExpr("=42")
+	assert.NotNil(t, err, "expression without field should be an error")
+	_, err = parseExpr("user")
+	assert.NotNil(t, err, "expression without operator should be an error")
+	_, err = parseExpr("user!42")
+	assert.NotNil(t, err, "expression with an invalid operator should be an error")
+}
+
+func TestRecordTime(t *testing.T) {
+	want := time.Date(2018, 12, 20, 9, 31, 23, 0, time.UTC)
+	for _, v := range []string{`"2018-12-20T09:31:23Z"`, `1545298283`, `1545298283000`, `1545298283000000`, `1545298283000000000`} {
+		got, ok := recordTime(decodeRecord(t, `{"level":"info","message":"m","time":`+v+`}`), "time")
+		assert.True(t, ok, "time %s should be found", v)
+		assert.True(t, want.Equal(got), "time %s should be parsed, got %v", v, got)
+	}
+	_, ok := recordTime(decodeRecord(t, `{"level":"info","message":"m"}`), "time")
+	assert.False(t, ok, "missing time should not be found")
+}
+
+func TestFilter(t *testing.T) {
+	f := &filter{
+		levels:  onelog.WARN | onelog.ERROR,
+		timeKey: "time",
+		since:   time.Unix(100, 0),
+		until:   time.Unix(200, 0),
+	}
+	assert.True(t, f.active(), "filter should be active")
+	assert.True(t, f.match(decodeRecord(t, `{"level":"warn","message":"m","time":100}`)), "entry should match")
+	assert.False(t, f.match(decodeRecord(t, `{"level":"info","message":"m","time":100}`)), "level should not match")
+	assert.False(t, f.match(decodeRecord(t, `{"level":"warn","message":"m","time":200}`)), "until should be excluded")
+	assert.False(t, f.match(decodeRecord(t, `{"level":"warn","message":"m","time":99}`)), "time before since should not match")
+	assert.False(t, f.match(decodeRecord(t, `{"level":"warn","message":"m"}`)), "entry without time should not match")
+	assert.False(t, (&filter{}).active(), "empty filter should not be active")
+}
@@ -0,0 +1,222 @@
+// Command onelog pretty-prints, filters and queries onelog JSON log streams.
+//
+// Usage:
+//
+//	onelog [flags] [file ...]
+//
+// Entries are read from the files, or from stdin if none is given, and written colored and aligned,
+// or as the original JSON lines with -json. Lines which are not entries are written as is unless filtering.
+//
+// Examples:
+//
+//	kubectl logs deploy/api | onelog -level warn+
+//	onelog -where user.id=42 -where 'duration_ms>500' -since 15m app.log
+//	onelog -follow -json -level error app.log | jq .
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/francoispqt/onelog"
+)
+
+// stringsFlag is a flag which can be repeated.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// pollInterval is the interval at which followed files are read after reaching their end.
+var pollInterval = 200 * time.Millisecond
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr, time.Now))
+}
+
+// run runs the command and returns its exit code, a followed file is read until ctx is done.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer, now func() time.Time) int {
+	fs := flag.NewFlagSet("onelog", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		level    = fs.String("level", "", `levels to keep: "warn", "warn+" for warn and above, or "info,error"`)
+		where    stringsFlag
+		since    = fs.String("since", "", "keep entries at or after this time, RFC 3339 or a duration before now like 15m")
+		until    = fs.String("until", "", "keep entries before this time, RFC 3339 or a duration before now like 15m")
+		follow   = fs.Bool("follow", false, "keep reading the file as it grows, like tail -f")
+		jsonOut  = fs.Bool("json", false, "write the original JSON lines instead of pretty-printing")
+		color    = fs.String("color", "auto", `colorize output: "auto", "always" or "never"`)
+		msgKey   = fs.String("msg-key", "message", "key of the message field, see onelog.MsgKey")
+		levelKey = fs.String("level-key", "level", "key of the level field, see onelog.LevelKey")
+		timeKey  = fs.String("time-key", "time", "key of the time field, RFC 3339 strings or unix timestamps")
+		contexts = fs.String("context", "", "comma separated names of context namespaces, see onelog.NewContext")
+	)
+	fs.Var(&where, "where", "field expression like user.id=42 or duration>500ms, operators are = != > >= < <=, can be repeated")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	f := &filter{timeKey: *timeKey}
+	var err error
+	if f.levels, err = parseLevels(*level); err != nil {
+		return usageError(stderr, err)
+	}
+	for _, w := range where {
+		expr, err := parseExpr(w)
+		if err != nil {
+			return usageError(stderr, err)
+		}
+		f.exprs = append(f.exprs, expr)
+	}
+	if f.since, err = parseTime(*since, now); err != nil {
+		return usageError(stderr, err)
+	}
+	if f.until, err = parseTime(*until, now); err != nil {
+		return usageError(stderr, err)
+	}
+	files := fs.Args()
+	if *follow && len(files) != 1 {
+		return usageError(stderr, errors.New("-follow needs exactly one file"))
+	}
+
+	r := onelog.NewReader(nil).Keys(*levelKey, *msgKey)
+	if *contexts != "" {
+		r.ContextNames(strings.Split(*contexts, ",")...)
+	}
+	out := bufio.NewWriter(stdout)
+	var p printer
+	if *jsonOut {
+		p = jsonPrinter{w: out}
+	} else {
+		useColor := *color == "always" || (*color == "auto" && isTerminal(stdout))
+		p = &prettyPrinter{w: out, color: useColor, timeKey: *timeKey}
+	}
+	c := &cat{r: r, f: f, p: p, w: out, flush: *follow}
+
+	if len(files) == 0 {
+		err = c.copy(stdin)
+	}
+	for _, name := range files {
+		if err = c.copyFile(ctx, name, *follow); err != nil {
+			break
+		}
+	}
+	if ferr := out.Flush(); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "onelog:", err)
+		return 1
+	}
+	return 0
+}
+
+func usageError(stderr io.Writer, err error) int {
+	fmt.Fprintln(stderr, "onelog:", err)
+	return 2
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// cat reads entries, filters them and prints them.
+type cat struct {
+	r *onelog.Reader
+	f *filter
+	p printer
+	w *bufio.Writer
+	// flush flushes the output after each entry, when following files.
+	flush bool
+}
+
+func (c *cat) copyFile(ctx context.Context, name string, follow bool) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if follow {
+		return c.copy(&followReader{ctx: ctx, r: file})
+	}
+	return c.copy(file)
+}
+
+func (c *cat) copy(r io.Reader) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if perr := c.line(line); perr != nil {
+				return perr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *cat) line(line []byte) error {
+	line = bytes.TrimRight(line, "\r\n")
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil
+	}
+	rec, err := c.r.Decode(line)
+	if err != nil {
+		// lines which are not entries, like panics, are kept unless filtering.
+		if !c.f.active() {
+			c.p.raw(line)
+		}
+	} else if c.f.match(rec) {
+		c.p.print(rec, line)
+	}
+	if c.flush {
+		return c.w.Flush()
+	}
+	return nil
+}
+
+// followReader reads a file waiting for more data at its end instead of returning io.EOF,
+// until its context is done.
+type followReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (f *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.r.Read(p)
+		if n > 0 || err != io.EOF {
+			return n, err
+		}
+		select {
+		case <-f.ctx.Done():
+			return 0, io.EOF
+		case <-time.After(pollInterval):
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testStream = `{"level":"info","message":"started","time":1545298283,"port":8080}
+{"level":"debug","message":"cache miss","time":1545298284,"key":"users"}
+panic: boom
+{"level":"warn","message":"slow request","time":1545298285,"duration_ms":750.5,"user":{"id":42}}
+{"level":"error","message":"request failed","time":1545298286,"duration_ms":12,"user":{"id":7}}
+`
+
+func testNow() time.Time {
+	return time.Unix(1545298287, 0)
+}
+
+func runTest(t *testing.T, stdin string, args ...string) (int, string, string) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := run(context.Background(), args, strings.NewReader(stdin), stdout, stderr, testNow)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	t.Run("pretty", func(t *testing.T) {
+		code, out, _ := runTest(t, testStream, "-time-key", "none")
+		assert.Equal(t, 0, code, "exit code should be 0")
+		assert.Equal(t, ""+
+			"INFO  started                                  time=1545298283 port=8080\n"+
+			"DEBUG cache miss                               time=1545298284 key=users\n"+
+			"panic: boom\n"+
+			"WARN  slow request                             time=1545298285 duration_ms=750.5 user={\"id\":42}\n"+
+			"ERROR request failed                           time=1545298286 duration_ms=12 user={\"id\":7}\n", out, "entries should be pretty-printed")
+	})
+	t.Run("level", func(t *testing.T) {
+		_, out, _ := runTest(t, testStream, "-json", "-level", "warn+")
+		assert.Equal(t, ""+
+			`{"level":"warn","message":"slow request","time":1545298285,"duration_ms":750.5,"user":{"id":42}}`+"\n"+
+			`{"level":"error","message":"request failed","time":1545298286,"duration_ms":12,"user":{"id":7}}`+"\n", out, "warn and error entries should be kept")
+	})
+	t.Run("where", func(t *testing.T) {
+		_, out, _ := runTest(t, testStream, "-json", "-where", "duration_ms>500ms", "-where", "user.id=42")
+		assert.Equal(t, `{"level":"warn","message":"slow request","time":1545298285,"duration_ms":750.5,"user":{"id":42}}`+"\n", out, "matching entry should be kept")
+	})
+	t.Run("time-range", func(t *testing.T) {
+		_, out, _ := runTest(t, testStream, "-json", "-since", "3s", "-until", "2018-12-20T09:31:26Z")
+		assert.Equal(t, ""+
+			`{"level":"debug","message":"cache miss","time":1545298284,"key":"users"}`+"\n"+
+			`{"level":"warn","message":"slow request","time":1545298285,"duration_ms":750.5,"user":{"id":42}}`+"\n", out, "entries in the range should be kept")
+	})
+	t.Run("custom-keys", func(t *testing.T) {
+		_, out, _ := runTest(t, `{"lvl":"error","msg":"failed","params":{"code":500}}`+"\n", "-level-key", "lvl", "-msg-key", "msg", "-context", "params", "-level", "error")
+		assert.Equal(t, "ERROR failed                                   params.code=500\n", out, "custom keys should be read")
+	})
+	t.Run("files", func(t *testing.T) {
+		dir := t.TempDir()
+		a, b := filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")
+		os.WriteFile(a, []byte(`{"level":"info","message":"a"}`+"\n"), 0o644)
+		os.WriteFile(b, []byte(`{"level":"info","message":"b"}`), 0o644)
+		code, out, _ := runTest(t, "", "-json", a, b)
+		assert.Equal(t, 0, code, "exit code should be 0")
+		assert.Equal(t, `{"level":"info","message":"a"}`+"\n"+`{"level":"info","message":"b"}`+"\n", out, "files should be read in order")
+	})
+	t.Run("errors", func(t *testing.T) {
+		code, _, stderr := runTest(t, "", "-level", "verbose")
+		assert.Equal(t, 2, code, "exit code should be 2")
+		assert.Equal(t, "onelog: unknown level \"verbose\"\n", stderr, "error should be written")
+		code, _, stderr = runTest(t, "", "-follow")
+		assert.Equal(t, 2, code, "exit code should be 2")
+		assert.Equal(t, "onelog: -follow needs exactly one file\n", stderr, "error should be written")
+		code, _, _ = runTest(t, "", filepath.Join(t.TempDir(), "missing.log"))
+		assert.Equal(t, 1, code, "exit code should be 1")
+	})
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestRunFollow(t *testing.T) {
+	prev := pollInterval
+	pollInterval = time.Millisecond
+	t.Cleanup(func() { pollInterval = prev })
+	name := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(name, []byte(`{"level":"info","message":"a"}`+"\n"), 0o644)
+	stdout := &syncBuffer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	code := make(chan int, 1)
+	go func() { code <- run(ctx, []string{"-json", "-follow", name}, nil, stdout, &bytes.Buffer{}, testNow) }()
+
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0)
+	assert.Nil(t, err, "err should be nil")
+	defer f.Close()
+	f.WriteString(`{"level":"info",`)
+	time.Sleep(10 * time.Millisecond)
+	f.WriteString(`"message":"b"}` + "\n")
+
+	want := `{"level":"info","message":"a"}` + "\n" + `{"level":"info","message":"b"}` + "\n"
+	deadline := time.Now().Add(2 * time.Second)
+	for stdout.String() != want && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, want, stdout.String(), "appended entries should be written")
+	cancel()
+	select {
+	case c := <-code:
+		assert.Equal(t, 0, c, "exit code should be 0")
+	case <-time.After(2 * time.Second):
+		t.Fatal("run should return when the context is done")
+	}
+}
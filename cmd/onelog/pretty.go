@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/francoispqt/onelog"
+)
+
+// printer writes entries and lines which are not entries.
+type printer interface {
+	print(rec onelog.Record, line []byte)
+	raw(line []byte)
+}
+
+// jsonPrinter writes the original lines.
+type jsonPrinter struct {
+	w *bufio.Writer
+}
+
+func (p jsonPrinter) print(_ onelog.Record, line []byte) {
+	p.raw(line)
+}
+
+func (p jsonPrinter) raw(line []byte) {
+	p.w.Write(line)
+	p.w.WriteByte('\n')
+}
+
+const (
+	// messageWidth is the width messages are padded to, so fields are aligned.
+	messageWidth = 40
+	// levelWidth is the width of the level column, the length of "ERROR".
+	levelWidth = 5
+	timeFormat = "2006-01-02 15:04:05.000"
+)
+
+const (
+	colorReset = "\x1b[0m"
+	colorFaint = "\x1b[2m"
+	colorRed   = "\x1b[31m"
+	colorCyan  = "\x1b[36m"
+)
+
+var levelColors = map[uint8]string{
+	onelog.DEBUG: "\x1b[34m",
+	onelog.INFO:  "\x1b[32m",
+	onelog.WARN:  "\x1b[33m",
+	onelog.ERROR: colorRed,
+	onelog.FATAL: "\x1b[1;31m",
+}
+
+// prettyPrinter writes entries on aligned columns: time, level, message and key=value fields.
+type prettyPrinter struct {
+	w       *bufio.Writer
+	color   bool
+	timeKey string
+	// loc is the location times are written in, time.Local if nil.
+	loc *time.Location
+}
+
+func (p *prettyPrinter) print(rec onelog.Record, line []byte) {
+	if t, ok := recordTime(rec, p.timeKey); ok {
+		loc := p.loc
+		if loc == nil {
+			loc = time.Local
+		}
+		p.colored(colorFaint, t.In(loc).Format(timeFormat))
+		p.w.WriteByte(' ')
+	}
+	level := escape(strings.ToUpper(rec.LevelText))
+	p.colored(levelColors[rec.Level], level)
+	p.pad(len(level), levelWidth)
+	p.w.WriteByte(' ')
+	msg := escape(rec.Message)
+	p.w.WriteString(msg)
+
+	first := true
+	field := func(prefix string, f onelog.Field) {
+		if first {
+			p.pad(len(msg), messageWidth)
+			first = false
+		}
+		p.w.WriteByte(' ')
+		k := escape(prefix + f.Key)
+		p.colored(colorCyan, k+"=")
+		v := value(f.Value)
+		if k == "error" || k == "err" {
+			p.colored(colorRed, v)
+		} else {
+			p.w.WriteString(v)
+		}
+	}
+	for _, f := range rec.Fields {
+		if f.Key != p.timeKey {
+			field("", f)
+		}
+	}
+	for _, f := range rec.Context {
+		field(rec.ContextName+".", f)
+	}
+	p.w.WriteByte('\n')
+}
+
+func (p *prettyPrinter) raw(line []byte) {
+	p.colored(colorFaint, escape(string(line)))
+	p.w.WriteByte('\n')
+}
+
+// colored writes s with the color if the printer uses colors.
+func (p *prettyPrinter) colored(color, s string) {
+	if !p.color || color == "" {
+		p.w.WriteString(s)
+		return
+	}
+	p.w.WriteString(color)
+	p.w.WriteString(s)
+	p.w.WriteString(colorReset)
+}
+
+func (p *prettyPrinter) pad(n, width int) {
+	for ; n < width; n++ {
+		p.w.WriteByte(' ')
+	}
+}
+
+// escape returns s with control characters escaped like in Go strings,
+// so entries cannot inject terminal escape sequences.
+func escape(s string) string {
+	if strings.IndexFunc(s, unicode.IsControl) < 0 {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if !unicode.IsControl(r) {
+			b.WriteRune(r)
+			continue
+		}
+		q := strconv.QuoteRune(r)
+		b.WriteString(q[1 : len(q)-1])
+	}
+	return b.String()
+}
+
+// value returns the text of a raw JSON value: strings are unquoted unless they contain
+// spaces, quotes, equal signs or control characters, other values are written as is.
+func value(raw []byte) string {
+	if len(raw) == 0 || raw[0] != '"' {
+		return string(raw)
+	}
+	s, err := strconv.Unquote(string(raw))
+	if err != nil {
+		return string(raw)
+	}
+	if s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return r == '"' || r == '=' || unicode.IsSpace(r) || unicode.IsControl(r)
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
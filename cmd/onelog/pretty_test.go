@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/francoispqt/onelog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrettyPrinter(t *testing.T) {
+	t.Run("plain", func(t *testing.T) {
+		b := &strings.Builder{}
+		w := bufio.NewWriter(b)
+		p := &prettyPrinter{w: w, timeKey: "time", loc: time.UTC}
+		p.print(decodeRecord(t, `{"level":"info","message":"started","time":1545298283,"port":8080,"host":"a b","params":{"code":403}}`), nil)
+		p.print(decodeRecord(t, `{"level":"error","message":"failed"}`), nil)
+		p.raw([]byte("panic: boom"))
+		w.Flush()
+		assert.Equal(t, ""+
+			"2018-12-20 09:31:23.000 INFO  started                                  port=8080 host=\"a b\" params.code=403\n"+
+			"ERROR failed\n"+
+			"panic: boom\n", b.String(), "entries should be aligned")
+	})
+	t.Run("color", func(t *testing.T) {
+		b := &strings.Builder{}
+		w := bufio.NewWriter(b)
+		p := &prettyPrinter{w: w, color: true, timeKey: "time"}
+		p.print(decodeRecord(t, `{"level":"warn","message":"m","error":"boom"}`), nil)
+		w.Flush()
+		assert.Equal(t, "\x1b[33mWARN\x1b[0m  m"+strings.Repeat(" ", 39)+" \x1b[36merror=\x1b[0m\x1b[31mboom\x1b[0m\n", b.String(), "entry should be colored")
+	})
+	t.Run("control-characters", func(t *testing.T) {
+		b := &strings.Builder{}
+		w := bufio.NewWriter(b)
+		p := &prettyPrinter{w: w, color: true, timeKey: "time"}
+		p.print(decodeRecord(t, `{"level":"info","message":"a\u001b[2Jb\nc"}`), nil)
+		p.raw([]byte("raw\x1b]0;title\x07"))
+		w.Flush()
+		assert.Equal(t, "\x1b[32mINFO\x1b[0m  a\\x1b[2Jb\\nc\n\x1b[2mraw\\x1b]0;title\\a\x1b[0m\n", b.String(), "control characters should be escaped")
+	})
+	t.Run("control-characters-in-keys", func(t *testing.T) {
+		b := &strings.Builder{}
+		w := bufio.NewWriter(b)
+		p := &prettyPrinter{w: w, timeKey: "time"}
+		rec := decodeRecord(t, `{"level":"info","message":"m","\u001b[31mkey":"v","params":{"c\u0007":1}}`)
+		rec.ContextName = "p\x1b[2J"
+		p.print(rec, nil)
+		w.Flush()
+		assert.Equal(t, "INFO  m"+strings.Repeat(" ", 39)+" \\x1b[31mkey=v p\\x1b[2J.c\\a=1\n", b.String(), "control characters in keys should be escaped")
+	})
+}
+
+func TestValue(t *testing.T) {
+	testCases := map[string]string{
+		`"bob"`:       `bob`,
+		`"a b"`:       `"a b"`,
+		`""`:          `""`,
+		`"k=v"`:       `"k=v"`,
+		`"line\nnew"`: `"line\nnew"`,
+		`42`:          `42`,
+		`{"id":1}`:    `{"id":1}`,
+		`null`:        `null`,
+	}
+	for raw, want := range testCases {
+		assert.Equal(t, want, value([]byte(raw)), "value of %s should be formatted", raw)
+	}
+}
+
+func TestJSONPrinter(t *testing.T) {
+	b := &strings.Builder{}
+	w := bufio.NewWriter(b)
+	p := jsonPrinter{w: w}
+	p.print(onelog.Record{}, []byte(`{"level":"info","message":"m"}`))
+	p.raw([]byte("panic: boom"))
+	w.Flush()
+	assert.Equal(t, "{\"level\":\"info\",\"message\":\"m\"}\npanic: boom\n", b.String(), "lines should be written as is")
+}
@@ -0,0 +1,241 @@
+package onelog
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/francoispqt/gojay"
+)
+
+// Extractor adds to an entry the fields found in a context, like a request ID, a tenant or a user.
+// It must not add any field when the context holds no value.
+type Extractor func(ctx context.Context, e Entry)
+
+type loggerKey struct{}
+
+var defaultLogger atomic.Value
+
+func init() {
+	defaultLogger.Store(New(nil, 0))
+}
+
+// DefaultLogger sets the logger returned by FromContext for contexts holding no logger.
+// The default is a logger discarding all entries.
+func DefaultLogger(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// WithLogger returns a copy of ctx holding the logger l.
+func WithLogger(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger held by ctx, or the default logger set with DefaultLogger.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return defaultLogger.Load().(*Logger)
+}
+
+// HasLogger reports whether ctx holds a logger set with WithLogger.
+func HasLogger(ctx context.Context) bool {
+	l, ok := ctx.Value(loggerKey{}).(*Logger)
+	return ok && l != nil
+}
+
+// Ctx returns the logger held by ctx with the fields extracted from ctx, see Logger.Ctx.
+func Ctx(ctx context.Context) *Logger {
+	return FromContext(ctx).Ctx(ctx)
+}
+
+// Extractors adds extractors of context fields to the logger and returns it.
+// The extractors are run by loggers returned by Ctx and by the Ctx logging methods, like InfoCtx,
+// and are inherited by loggers derived with With and WithContext.
+func (l *Logger) Extractors(extractors ...Extractor) *Logger {
+	l.extractors = append(l.extractors[:len(l.extractors):len(l.extractors)], extractors...)
+	return l
+}
+
+// Ctx returns a copy of the logger adding to each entry the fields extracted from ctx by the logger's extractors.
+// The logger is returned as is if it has no extractors, the copy has none so the fields are not extracted twice.
+// Ctx allocates the copy, loggers should be derived once per context, like in a middleware,
+// or entries logged with the Ctx logging methods, like InfoCtx, which do not allocate.
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	if len(l.extractors) == 0 {
+		return l
+	}
+	extractors := l.extractors
+	nL := l.With(func(e Entry) {
+		for _, ex := range extractors {
+			ex(ctx, e)
+		}
+	})
+	// the extractors have been applied, they must not run again for entries
+	// logged with the Ctx logging methods or loggers derived with Ctx.
+	nL.extractors = nil
+	return nL
+}
+
+// extract adds to the entry the fields extracted from its context by the logger's extractors.
+func (l *Logger) extract(e Entry) {
+	if e.ctx == nil {
+		return
+	}
+	for _, ex := range l.extractors {
+		ex(e.ctx, e)
+	}
+}
+
+// InfoCtx logs an entry with INFO level and the fields extracted from ctx.
+func (l *Logger) InfoCtx(ctx context.Context, msg string) {
+	l.logCtx(ctx, INFO, msg, nil)
+}
+
+// InfoWithCtx returns a ChainEntry with INFO level and the fields extracted from ctx.
+func (l *Logger) InfoWithCtx(ctx context.Context, msg string) ChainEntry {
+	return l.withCtx(ctx, INFO, msg)
+}
+
+// InfoWithFieldsCtx logs an entry with INFO level, the fields extracted from ctx and the fields added by fields.
+func (l *Logger) InfoWithFieldsCtx(ctx context.Context, msg string, fields func(Entry)) {
+	l.logCtx(ctx, INFO, msg, fields)
+}
+
+// DebugCtx logs an entry with DEBUG level and the fields extracted from ctx.
+func (l *Logger) DebugCtx(ctx context.Context, msg string) {
+	l.logCtx(ctx, DEBUG, msg, nil)
+}
+
+// DebugWithCtx returns a ChainEntry with DEBUG level and the fields extracted from ctx.
+func (l *Logger) DebugWithCtx(ctx context.Context, msg string) ChainEntry {
+	return l.withCtx(ctx, DEBUG, msg)
+}
+
+// DebugWithFieldsCtx logs an entry with DEBUG level, the fields extracted from ctx and the fields added by fields.
+func (l *Logger) DebugWithFieldsCtx(ctx context.Context, msg string, fields func(Entry)) {
+	l.logCtx(ctx, DEBUG, msg, fields)
+}
+
+// WarnCtx logs an entry with WARN level and the fields extracted from ctx.
+func (l *Logger) WarnCtx(ctx context.Context, msg string) {
+	l.logCtx(ctx, WARN, msg, nil)
+}
+
+// WarnWithCtx returns a ChainEntry with WARN level and the fields extracted from ctx.
+func (l *Logger) WarnWithCtx(ctx context.Context, msg string) ChainEntry {
+	return l.withCtx(ctx, WARN, msg)
+}
+
+// WarnWithFieldsCtx logs an entry with WARN level, the fields extracted from ctx and the fields added by fields.
+func (l *Logger) WarnWithFieldsCtx(ctx context.Context, msg string, fields func(Entry)) {
+	l.logCtx(ctx, WARN, msg, fields)
+}
+
+// ErrorCtx logs an entry with ERROR level and the fields extracted from ctx.
+func (l *Logger) ErrorCtx(ctx context.Context, msg string) {
+	l.logCtx(ctx, ERROR, msg, nil)
+}
+
+// ErrorWithCtx returns a ChainEntry with ERROR level and the fields extracted from ctx.
+func (l *Logger) ErrorWithCtx(ctx context.Context, msg string) ChainEntry {
+	return l.withCtx(ctx, ERROR, msg)
+}
+
+// ErrorWithFieldsCtx logs an entry with ERROR level, the fields extracted from ctx and the fields added by fields.
+func (l *Logger) ErrorWithFieldsCtx(ctx context.Context, msg string, fields func(Entry)) {
+	l.logCtx(ctx, ERROR, msg, fields)
+}
+
+// FatalCtx logs an entry with FATAL level and the fields extracted from ctx, then exits.
+func (l *Logger) FatalCtx(ctx context.Context, msg string) {
+	l.logCtx(ctx, FATAL, msg, nil)
+}
+
+// FatalWithCtx returns a ChainEntry with FATAL level and the fields extracted from ctx,
+// writing it exits.
+func (l *Logger) FatalWithCtx(ctx context.Context, msg string) ChainEntry {
+	return l.withCtx(ctx, FATAL, msg)
+}
+
+// FatalWithFieldsCtx logs an entry with FATAL level, the fields extracted from ctx and the fields added by fields,
+// then exits.
+func (l *Logger) FatalWithFieldsCtx(ctx context.Context, msg string, fields func(Entry)) {
+	l.logCtx(ctx, FATAL, msg, fields)
+}
+
+// accepts reports whether an entry with the level and message must be logged,
+// FATAL entries are never sampled or rate limited.
+func (l *Logger) accepts(level uint8, msg string) bool {
+	return level&l.levels != 0 && (level == FATAL || !l.drop(level, msg))
+}
+
+// logCtx logs an entry with the fields extracted from ctx and the fields added by fields, which may be nil.
+func (l *Logger) logCtx(ctx context.Context, level uint8, msg string, fields func(Entry)) {
+	if !l.accepts(level, msg) {
+		return
+	}
+	e := Entry{l: l, ctx: ctx, Level: level, Message: msg}
+	e.enc = gojay.BorrowEncoder(l.w)
+
+	if l.contextName == "" {
+		l.beginEntry(level, msg, e)
+		l.runHook(e)
+	} else {
+		l.openEntry(e.enc)
+	}
+
+	if fields != nil {
+		fields(e)
+	}
+	l.closeEntry(e)
+	l.finalizeIfContext(e)
+
+	e.enc.Release()
+	if level == FATAL {
+		l.exit(1)
+	}
+}
+
+// withCtx returns a ChainEntry with the fields extracted from ctx.
+func (l *Logger) withCtx(ctx context.Context, level uint8, msg string) ChainEntry {
+	e := ChainEntry{
+		Entry: Entry{
+			l:       l,
+			ctx:     ctx,
+			Level:   level,
+			Message: msg,
+		},
+	}
+	e.disabled = !l.accepts(level, msg)
+	if e.disabled {
+		return e
+	}
+	e.exit = level == FATAL
+
+	e.Entry.enc = gojay.BorrowEncoder(l.w)
+	if l.contextName == "" {
+		l.beginEntry(level, msg, e.Entry)
+		l.runHook(e.Entry)
+		return e
+	}
+
+	l.openEntry(e.Entry.enc)
+	return e
+}
+
+// ContextValue returns an extractor adding the value held by the context for key as the field k.
+func ContextValue(key interface{}, k string) Extractor {
+	return func(ctx context.Context, e Entry) {
+		switch v := ctx.Value(key).(type) {
+		case nil:
+		case string:
+			e.String(k, v)
+		case fmt.Stringer:
+			e.String(k, v.String())
+		default:
+			e.Any(k, v)
+		}
+	}
+}
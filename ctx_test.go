@@ -0,0 +1,128 @@
+package onelog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIDKey struct{}
+
+type tenantKey struct{}
+
+type testUser struct {
+	ID   string `json:"id"`
+	Role string `json:"role"`
+}
+
+type userKey struct{}
+
+func TestContextLogger(t *testing.T) {
+	t.Run("from-context", func(t *testing.T) {
+		logger := New(&bytes.Buffer{}, ALL)
+		ctx := WithLogger(context.Background(), logger)
+		assert.Equal(t, logger, FromContext(ctx), "FromContext should return the logger held by the context")
+	})
+	t.Run("default-no-op", func(t *testing.T) {
+		logger := FromContext(context.Background())
+		assert.NotNil(t, logger, "FromContext should never return nil")
+		logger.Info("hello")
+		logger.InfoWith("hello").String("foo", "bar").Write()
+	})
+	t.Run("default-configured", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		fallback := New(w, ALL)
+		DefaultLogger(fallback)
+		defer DefaultLogger(New(nil, 0))
+		FromContext(context.Background()).Info("hello")
+		assert.Equal(t, `{"level":"info","message":"hello"}`+"\n", w.String(), "bytes written to the writer dont equal expected result")
+	})
+}
+
+func TestContextExtractors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+	ctx = context.WithValue(ctx, userKey{}, testUser{ID: "42", Role: "admin"})
+	t.Run("fields", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Extractors(
+			ContextValue(requestIDKey{}, "request_id"),
+			ContextValue(tenantKey{}, "tenant"),
+			ContextValue(userKey{}, "user"),
+			ContextValue("missing", "missing"),
+		)
+		logger.Ctx(ctx).InfoWith("hello").Int("foo", 1).Write()
+		json := `{"level":"info","message":"hello","request_id":"req-1","tenant":"acme","user":{"id":"42","role":"admin"},"foo":1}` + "\n"
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("middleware", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := NewContext(w, ALL, "params").
+			With(func(e Entry) { e.String("service", "api") }).
+			Extractors(func(ctx context.Context, e Entry) {
+				if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+					e.String("request_id", id)
+				}
+			})
+		// a middleware stores the logger in the request context, deep code retrieves it.
+		reqCtx := WithLogger(ctx, logger)
+		Ctx(reqCtx).Warn("hello")
+		json := `{"level":"warn","message":"hello","params":{"service":"api","request_id":"req-1"}}` + "\n"
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("no-extractors", func(t *testing.T) {
+		logger := New(&bytes.Buffer{}, ALL)
+		assert.Equal(t, logger, logger.Ctx(ctx), "Ctx should return the logger as is without extractors")
+	})
+	t.Run("inherited", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		parent := New(w, ALL).Extractors(ContextValue(requestIDKey{}, "request_id"))
+		child := parent.With(func(e Entry) { e.Int("child", 1) }).Extractors(ContextValue(tenantKey{}, "tenant"))
+		parent.Ctx(ctx).Info("parent")
+		child.Ctx(ctx).Info("child")
+		json := `{"level":"info","message":"parent","request_id":"req-1"}` + "\n" +
+			`{"level":"info","message":"child","child":1,"request_id":"req-1","tenant":"acme"}` + "\n"
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("ctx-methods", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).
+			With(func(e Entry) { e.String("service", "api") }).
+			Extractors(ContextValue(requestIDKey{}, "request_id"))
+		exited := 0
+		logger.ExitFn = func(int) { exited++ }
+		logger.InfoCtx(ctx, "info")
+		logger.DebugWithCtx(ctx, "debug").Int("foo", 1).Write()
+		logger.WarnWithFieldsCtx(ctx, "warn", func(e Entry) { e.Int("foo", 1) })
+		logger.ErrorCtx(context.Background(), "error")
+		logger.FatalWithCtx(ctx, "fatal").Write()
+		json := `{"level":"info","message":"info","service":"api","request_id":"req-1"}` + "\n" +
+			`{"level":"debug","message":"debug","service":"api","request_id":"req-1","foo":1}` + "\n" +
+			`{"level":"warn","message":"warn","service":"api","request_id":"req-1","foo":1}` + "\n" +
+			`{"level":"error","message":"error","service":"api"}` + "\n" +
+			`{"level":"fatal","message":"fatal","service":"api","request_id":"req-1"}` + "\n"
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+		assert.Equal(t, 1, exited, "logger should exit after the fatal entry")
+	})
+	t.Run("ctx-methods-context", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := NewContext(w, INFO, "params").Extractors(ContextValue(requestIDKey{}, "request_id"))
+		logger.InfoWithFieldsCtx(ctx, "hello", func(e Entry) { e.Int("foo", 1) })
+		logger.DebugCtx(ctx, "disabled")
+		json := `{"level":"info","message":"hello","params":{"foo":1,"request_id":"req-1"}}` + "\n"
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("enriched-once", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Extractors(ContextValue(requestIDKey{}, "request_id"))
+		// a middleware enriches the logger once, deep code logs with the context.
+		reqCtx := WithLogger(ctx, logger.Ctx(ctx))
+		FromContext(reqCtx).InfoCtx(reqCtx, "deep")
+		Ctx(reqCtx).Info("deep")
+		json := `{"level":"info","message":"deep","request_id":"req-1"}` + "\n" +
+			`{"level":"info","message":"deep","request_id":"req-1"}` + "\n"
+		assert.Equal(t, json, w.String(), "fields should be extracted once")
+	})
+}
@@ -0,0 +1,157 @@
+package onelog
+
+import (
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Deduper suppresses repeated consecutive log entries.
+//
+// An entry is a duplicate of the previous one if it has the same level, message and fields.
+// Duplicates are suppressed until a different entry is written or the window expires,
+// then a single follow-up entry is written: a copy of the repeated entry with a "repeated" count
+// and "first_seen" and "last_seen" timestamps. When the window expires, the follow-up entry is
+// written by a timer, Flush writes it right away, typically before the program exits.
+//
+// A Deduper writes to the writer of the logger it is set on with Logger.Dedup and is shared by
+// loggers derived from it with With and WithContext, it is safe for concurrent use.
+// It must not be set on loggers with different writers.
+type Deduper struct {
+	mu       sync.Mutex
+	window   int64
+	ignore   []string
+	w        io.Writer
+	hash     uint64
+	last     []byte
+	buf      []byte
+	repeated uint64
+	first    int64
+	lastSeen int64
+	// run identifies the current run of duplicates for the timer writing its follow-up entry.
+	run   uint64
+	timer *time.Timer
+}
+
+// NewDeduper returns a Deduper suppressing duplicates during at most window.
+func NewDeduper(window time.Duration) *Deduper {
+	return &Deduper{
+		window: int64(window),
+	}
+}
+
+// Ignore sets root keys ignored when comparing entries,
+// typically the time field added by a hook.
+func (d *Deduper) Ignore(keys ...string) *Deduper {
+	d.ignore = keys
+	return d
+}
+
+// Flush writes the follow-up entry of the current run of duplicates if any.
+func (d *Deduper) Flush() {
+	d.mu.Lock()
+	d.flush()
+	d.mu.Unlock()
+}
+
+// Dedup sets the deduper used by the logger, bound to the logger's writer, and returns it.
+// The deduper is shared by loggers derived with With and WithContext.
+func (l *Logger) Dedup(d *Deduper) *Logger {
+	d.bind(l.w)
+	l.dedup = d
+	return l
+}
+
+// bind makes the deduper write to w.
+func (d *Deduper) bind(w io.Writer) {
+	d.mu.Lock()
+	d.w = w
+	d.mu.Unlock()
+}
+
+func (d *Deduper) write(b []byte) {
+	h := d.sum(b)
+	t := now().UnixNano()
+
+	d.mu.Lock()
+	if h == d.hash && len(d.last) > 0 && t-d.first < d.window {
+		d.repeated++
+		d.lastSeen = t
+		if d.repeated == 1 {
+			d.expireAfter(time.Duration(d.first + d.window - t))
+		}
+		d.mu.Unlock()
+		return
+	}
+	d.flush()
+	d.w.Write(b)
+	d.run++
+	d.hash = h
+	d.last = append(d.last[:0], b...)
+	d.first = t
+	d.lastSeen = t
+	d.mu.Unlock()
+}
+
+// expireAfter starts the timer writing the follow-up entry of the current run when the window expires,
+// d.mu must be held.
+func (d *Deduper) expireAfter(delay time.Duration) {
+	run := d.run
+	d.timer = time.AfterFunc(delay, func() {
+		d.mu.Lock()
+		if d.run == run {
+			d.flush()
+		}
+		d.mu.Unlock()
+	})
+}
+
+// flush writes the follow-up entry, d.mu must be held.
+func (d *Deduper) flush() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.repeated == 0 || len(d.last) < len(logClose) {
+		return
+	}
+	d.buf = append(d.buf[:0], d.last[:len(d.last)-len(logClose)]...)
+	d.buf = append(d.buf, `,"repeated":`...)
+	d.buf = strconv.AppendUint(d.buf, d.repeated, 10)
+	d.buf = append(d.buf, `,"first_seen":"`...)
+	d.buf = time.Unix(0, d.first).UTC().AppendFormat(d.buf, time.RFC3339Nano)
+	d.buf = append(d.buf, `","last_seen":"`...)
+	d.buf = time.Unix(0, d.lastSeen).UTC().AppendFormat(d.buf, time.RFC3339Nano)
+	d.buf = append(d.buf, '"')
+	d.buf = append(d.buf, logClose...)
+	d.w.Write(d.buf)
+	d.repeated = 0
+	d.last = d.last[:0]
+}
+
+// sum hashes the entry with FNV-1a, skipping ignored root keys.
+func (d *Deduper) sum(b []byte) uint64 {
+	h := uint64(14695981039346656037)
+	hash := func(p []byte) {
+		for _, c := range p {
+			h ^= uint64(c)
+			h *= 1099511628211
+		}
+	}
+	if len(d.ignore) == 0 {
+		hash(b)
+		return h
+	}
+	eachMember(b, func(k []byte, start, end int) bool {
+		for _, ignored := range d.ignore {
+			if string(k) == ignored {
+				return true
+			}
+		}
+		hash(k)
+		hash(b[start:end])
+		return true
+	})
+	return h
+}
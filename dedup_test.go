@@ -0,0 +1,149 @@
+package onelog
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeduper(t *testing.T) {
+	t.Run("repeated-then-different", func(t *testing.T) {
+		start := time.Date(2018, 5, 6, 2, 21, 1, 0, time.UTC)
+		defer setNow(start)()
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Dedup(NewDeduper(time.Minute))
+		for i := 0; i < 4; i++ {
+			now = func() time.Time { return start.Add(time.Duration(i) * time.Second) }
+			logger.ErrorWithFields("db unreachable", func(e Entry) {
+				e.String("host", "db1")
+			})
+		}
+		logger.InfoWith("db reachable").String("host", "db1").Write()
+		assert.Equal(
+			t,
+			`{"level":"error","message":"db unreachable","host":"db1"}`+"\n"+
+				`{"level":"error","message":"db unreachable","host":"db1","repeated":3,`+
+				`"first_seen":"2018-05-06T02:21:01Z","last_seen":"2018-05-06T02:21:04Z"}`+"\n"+
+				`{"level":"info","message":"db reachable","host":"db1"}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+	})
+	t.Run("different-fields", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Dedup(NewDeduper(time.Minute))
+		logger.ErrorWith("db unreachable").String("host", "db1").Write()
+		logger.ErrorWith("db unreachable").String("host", "db2").Write()
+		logger.WarnWith("db unreachable").String("host", "db2").Write()
+		assert.Equal(t, 3, bytes.Count(w.Bytes(), []byte("\n")), "3 entries should have been written")
+	})
+	t.Run("window-expired", func(t *testing.T) {
+		start := time.Date(2018, 5, 6, 2, 21, 1, 0, time.UTC)
+		defer setNow(start)()
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Dedup(NewDeduper(time.Second))
+		logger.Error("db unreachable")
+		logger.Error("db unreachable")
+		now = func() time.Time { return start.Add(2 * time.Second) }
+		logger.Error("db unreachable")
+		assert.Equal(
+			t,
+			`{"level":"error","message":"db unreachable"}`+"\n"+
+				`{"level":"error","message":"db unreachable","repeated":1,`+
+				`"first_seen":"2018-05-06T02:21:01Z","last_seen":"2018-05-06T02:21:01Z"}`+"\n"+
+				`{"level":"error","message":"db unreachable"}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+	})
+	t.Run("ignore-keys-and-flush", func(t *testing.T) {
+		start := time.Date(2018, 5, 6, 2, 21, 1, 0, time.UTC)
+		defer setNow(start)()
+		w := &bytes.Buffer{}
+		d := NewDeduper(time.Minute).Ignore("time")
+		i := 0
+		logger := NewContext(w, ALL, "params").
+			Hook(func(e Entry) {
+				i++
+				e.Int("time", i)
+			}).
+			Dedup(d)
+		logger.ErrorWithFields("db unreachable", func(e Entry) {
+			e.String("host", "db1")
+		})
+		logger.ErrorWithFields("db unreachable", func(e Entry) {
+			e.String("host", "db1")
+		})
+		d.Flush()
+		d.Flush()
+		assert.Equal(
+			t,
+			`{"level":"error","message":"db unreachable","time":1,"params":{"host":"db1"}}`+"\n"+
+				`{"level":"error","message":"db unreachable","time":1,"params":{"host":"db1"},"repeated":1,`+
+				`"first_seen":"2018-05-06T02:21:01Z","last_seen":"2018-05-06T02:21:01Z"}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+	})
+}
+
+// structWriter is a writer which is not comparable.
+type structWriter struct {
+	mu    *sync.Mutex
+	lines *[]string
+	_     []int
+}
+
+func (w structWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	*w.lines = append(*w.lines, string(p))
+	return len(p), nil
+}
+
+func (w structWriter) len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(*w.lines)
+}
+
+func TestDeduperWriter(t *testing.T) {
+	t.Run("uncomparable-writer", func(t *testing.T) {
+		w := structWriter{mu: &sync.Mutex{}, lines: &[]string{}}
+		logger := New(w, ALL).Dedup(NewDeduper(time.Minute))
+		logger.Info("message")
+		logger.Info("message")
+		logger.With(func(e Entry) {}).Info("other")
+		assert.Equal(t, 3, w.len(), "entries and follow-up entry should have been written")
+	})
+	t.Run("window-expired-timer", func(t *testing.T) {
+		w := structWriter{mu: &sync.Mutex{}, lines: &[]string{}}
+		logger := New(w, ALL).Dedup(NewDeduper(20 * time.Millisecond))
+		logger.Error("db unreachable")
+		logger.Error("db unreachable")
+		logger.Error("db unreachable")
+		deadline := time.Now().Add(2 * time.Second)
+		for w.len() < 2 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		assert.Len(t, *w.lines, 2, "follow-up entry should have been written when the window expired")
+		assert.Contains(t, (*w.lines)[1], `"repeated":2`, "follow-up entry should hold the repetitions")
+	})
+}
+
+func TestEachMember(t *testing.T) {
+	var keys []string
+	ok := eachMember([]byte(`{"a":1, "b" : {"c":[1,"}",{}]},"d":"e\"f","g":true}`), func(k []byte, start, end int) bool {
+		keys = append(keys, string(k))
+		return true
+	})
+	assert.True(t, ok, "object should be well formed")
+	assert.Equal(t, []string{"a", "b", "d", "g"}, keys, "keys should be the object's root keys")
+	assert.False(t, eachMember([]byte(`{"a":1,"b"`), func(k []byte, start, end int) bool { return true }), "object should be malformed")
+	assert.False(t, eachMember([]byte(`[1]`), func(k []byte, start, end int) bool { return true }), "array is not an object")
+}
@@ -0,0 +1,44 @@
+package onelog
+
+import (
+	"github.com/francoispqt/gojay"
+)
+
+// Dict returns a new entry to build a nested object with the chain API,
+// it must be added to an entry with Dict which releases it.
+// Dictionaries can be nested in other dictionaries.
+//
+// Dictionaries are bound to the logger, so their fields are redacted, encrypted
+// and errors encoded in rich mode like the fields of the logger's entries.
+//
+// Example:
+//
+//	logger.InfoWith("user created").
+//		Dict("user", logger.Dict().String("id", id).Int("age", 3)).
+//		Write()
+func (l *Logger) Dict() Entry {
+	enc := gojay.BorrowEncoder(nil)
+	enc.AppendByte('{')
+	return Entry{enc: enc, l: l}
+}
+
+// Dict adds to the log entry the object built with the dictionary d returned by Logger.Dict and releases d.
+func (e Entry) Dict(k string, d Entry) Entry {
+	if !e.redact(k) {
+		e.key(k)
+		e.enc.AppendBytes(d.enc.Buf())
+		e.enc.AppendByte('}')
+	}
+	d.enc.Release()
+	return e
+}
+
+// Dict adds to the log entry the object built with the dictionary d returned by Logger.Dict and releases d.
+func (e ChainEntry) Dict(k string, d Entry) ChainEntry {
+	if e.disabled {
+		d.enc.Release()
+		return e
+	}
+	e.Entry.Dict(k, d)
+	return e
+}
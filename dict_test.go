@@ -0,0 +1,62 @@
+package onelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDict(t *testing.T) {
+	t.Run("chain-entry", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO)
+		logger.InfoWith("hello").
+			Dict("user", logger.Dict().String("id", "123").Int("age", 3)).
+			Int("after", 1).
+			Write()
+		assert.Equal(t, `{"level":"info","message":"hello","user":{"id":"123","age":3},"after":1}`+"\n", string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("entry-nested", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO)
+		logger.InfoWithFields("hello", func(e Entry) {
+			e.Dict("user", logger.Dict().
+				String("id", "123").
+				Dict("address", logger.Dict().String("city", "Paris").Dict("geo", logger.Dict().Float("lat", 48.85))).
+				Dict("empty", logger.Dict()).
+				Strings("tags", []string{"a"}))
+		})
+		json := `{"level":"info","message":"hello","user":{"id":"123","address":{"city":"Paris","geo":{"lat":48.85}},"empty":{},"tags":["a"]}}` + "\n"
+		assert.Equal(t, json, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("context", func(t *testing.T) {
+		w := newWriter()
+		logger := NewContext(w, INFO, "params")
+		logger.InfoWith("hello").Dict("user", logger.Dict().String("id", "123")).Write()
+		assert.Equal(t, `{"level":"info","message":"hello","params":{"user":{"id":"123"}}}`+"\n", string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("redacted", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO).Redactor(NewRedactor().Keys("user"))
+		logger.InfoWith("hello").Dict("user", logger.Dict().String("id", "123")).Write()
+		assert.Equal(t, `{"level":"info","message":"hello","user":"[REDACTED]"}`+"\n", string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("bound-to-logger", func(t *testing.T) {
+		w := newWriter()
+		encrypter, err := NewEncrypter("k1", []byte("0123456789abcdef"))
+		assert.Nil(t, err, "err should be nil")
+		logger := New(w, INFO).Redactor(NewRedactor().Keys("password")).Encrypter(encrypter)
+		logger.InfoWith("hello").
+			Dict("user", logger.Dict().
+				String("password", "secret").
+				Dict("card", logger.Dict().Encrypted("number", "4111"))).
+			Write()
+		assert.Regexp(t, `^\{"level":"info","message":"hello","user":\{"password":"\[REDACTED\]","card":\{"number":"enc:k1:[A-Za-z0-9+/=]+"\}\}\}`+"\n", string(w.b), "nested fields should be redacted and encrypted")
+	})
+	t.Run("disabled", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, DEBUG)
+		logger.InfoWith("hello").Dict("user", logger.Dict().String("id", "123")).Write()
+		assert.Equal(t, ``, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+}
@@ -0,0 +1,218 @@
+package onelog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/francoispqt/gojay"
+)
+
+// encryptedPrefix prefixes encrypted values, it is followed by the key ID, a colon
+// and the base64 encoded nonce and ciphertext.
+const encryptedPrefix = "enc:"
+
+// randReader is the source of nonces, it is a variable so tests can make it fail.
+var randReader = rand.Reader
+
+// Encrypter encrypts field values with AES-GCM.
+type Encrypter struct {
+	keyID string
+	aead  cipher.AEAD
+}
+
+// NewEncrypter returns an Encrypter using the AES key (16, 24 or 32 bytes) identified by keyID.
+// The key ID is written in clear with each encrypted value so keys can be rotated.
+func NewEncrypter(keyID string, key []byte) (*Encrypter, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Encrypter{keyID: keyID, aead: aead}, nil
+}
+
+// Encrypter sets the encrypter used by the logger for encrypted fields and returns it.
+// The encrypter is shared by loggers derived with With and WithContext.
+func (l *Logger) Encrypter(enc *Encrypter) *Logger {
+	l.encrypter = enc
+	return l
+}
+
+// encrypt returns the encrypted value v of the field k, the key being used as additional data
+// so a value cannot be moved to another field.
+// It fails closed, returning "[REDACTED]" if no nonce can be generated.
+func (enc *Encrypter) encrypt(k, v string) string {
+	nonceSize := enc.aead.NonceSize()
+	b := make([]byte, nonceSize, nonceSize+len(v)+enc.aead.Overhead())
+	if _, err := io.ReadFull(randReader, b); err != nil {
+		return "[REDACTED]"
+	}
+	b = enc.aead.Seal(b, b, []byte(v), []byte(k))
+	return encryptedPrefix + enc.keyID + ":" + base64.StdEncoding.EncodeToString(b)
+}
+
+// encrypted returns the value to write for the encrypted field k.
+func (e Entry) encrypted(k, v string) string {
+	if e.l == nil || e.l.encrypter == nil {
+		return "[REDACTED]"
+	}
+	return e.l.encrypter.encrypt(k, v)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// DecryptReader reads a log stream and re-renders it with encrypted fields decrypted.
+// Values encrypted with an unknown key or which cannot be decrypted are left untouched.
+type DecryptReader struct {
+	r     *bufio.Reader
+	keys  map[string][]byte
+	aeads map[string]cipher.AEAD
+	buf   bytes.Buffer
+	err   error
+}
+
+// NewDecryptReader returns a DecryptReader reading from r and decrypting values with keys, indexed by key ID.
+func NewDecryptReader(r io.Reader, keys map[string][]byte) *DecryptReader {
+	return &DecryptReader{
+		r:     bufio.NewReader(r),
+		keys:  keys,
+		aeads: make(map[string]cipher.AEAD, len(keys)),
+	}
+}
+
+// Read reads the decrypted stream.
+func (d *DecryptReader) Read(p []byte) (int, error) {
+	for d.buf.Len() == 0 && d.err == nil {
+		var line []byte
+		line, d.err = d.r.ReadBytes('\n')
+		if len(line) > 0 {
+			d.decryptLine(line)
+		}
+	}
+	if d.buf.Len() > 0 {
+		return d.buf.Read(p)
+	}
+	return 0, d.err
+}
+
+func (d *DecryptReader) decryptLine(line []byte) {
+	trimmed := bytes.TrimRight(line, "\r\n")
+	if !bytes.Contains(trimmed, []byte(`"`+encryptedPrefix)) {
+		d.buf.Write(line)
+		return
+	}
+	out, ok := d.value(nil, trimmed, nil)
+	if !ok {
+		d.buf.Write(line)
+		return
+	}
+	d.buf.Write(out)
+	d.buf.Write(line[len(trimmed):])
+}
+
+// value appends to out the JSON value v with encrypted strings decrypted, k being the key of v.
+func (d *DecryptReader) value(out, v, k []byte) ([]byte, bool) {
+	switch v[0] {
+	case '"':
+		return d.string(out, v, k), true
+	case '{':
+		out = append(out, '{')
+		first := true
+		ok := eachMember(v, func(key []byte, start, end int) bool {
+			if !first {
+				out = append(out, ',')
+			}
+			first = false
+			out = append(out, '"')
+			out = append(out, key...)
+			out = append(out, '"', ':')
+			out, _ = d.value(out, v[start:end], key)
+			return true
+		})
+		return append(out, '}'), ok
+	case '[':
+		out = append(out, '[')
+		first := true
+		ok := eachElement(v, func(start, end int) bool {
+			if !first {
+				out = append(out, ',')
+			}
+			first = false
+			out, _ = d.value(out, v[start:end], k)
+			return true
+		})
+		return append(out, ']'), ok
+	default:
+		return append(out, v...), true
+	}
+}
+
+func (d *DecryptReader) string(out, v, k []byte) []byte {
+	s := string(v[1 : len(v)-1])
+	if !strings.HasPrefix(s, encryptedPrefix) {
+		return append(out, v...)
+	}
+	s = s[len(encryptedPrefix):]
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return append(out, v...)
+	}
+	aead, ok := d.aead(s[:i])
+	if !ok {
+		return append(out, v...)
+	}
+	b, err := base64.StdEncoding.DecodeString(s[i+1:])
+	if err != nil || len(b) < aead.NonceSize() {
+		return append(out, v...)
+	}
+	plain, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], unescapeKey(k))
+	if err != nil {
+		return append(out, v...)
+	}
+	enc := gojay.BorrowEncoder(nil)
+	enc.AppendString(string(plain))
+	out = append(out, enc.Buf()...)
+	enc.Release()
+	return out
+}
+
+// unescapeKey returns the JSON escaped key k as it was passed to Encrypted, which is the additional data of its value.
+func unescapeKey(k []byte) []byte {
+	if bytes.IndexByte(k, '\\') < 0 {
+		return k
+	}
+	var s string
+	quoted := make([]byte, 0, len(k)+2)
+	quoted = append(append(append(quoted, '"'), k...), '"')
+	if err := gojay.Unmarshal(quoted, &s); err != nil {
+		return k
+	}
+	return []byte(s)
+}
+
+func (d *DecryptReader) aead(keyID string) (cipher.AEAD, bool) {
+	if aead, ok := d.aeads[keyID]; ok {
+		return aead, true
+	}
+	key, ok := d.keys[keyID]
+	if !ok {
+		return nil, false
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, false
+	}
+	d.aeads[keyID] = aead
+	return aead, true
+}
@@ -0,0 +1,95 @@
+package onelog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncrypted(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	encrypter, err := NewEncrypter("k1", key)
+	assert.Nil(t, err, "err should be nil")
+
+	t.Run("encrypt-and-decrypt", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Encrypter(encrypter)
+		logger.InfoWith("hello").
+			Encrypted("patientID", "P-123\"456").
+			String("foo", "bar").
+			Write()
+		logger.WithContext("params").InfoWithFields("hello", func(e Entry) {
+			e.ObjectFunc("account", func(e Entry) {
+				e.Encrypted("number", "FR76 3000")
+			})
+		})
+		assert.Regexp(t, `^\{"level":"info","message":"hello","patientID":"enc:k1:[A-Za-z0-9+/=]+","foo":"bar"\}`+"\n", w.String(), "value should be encrypted")
+		assert.NotContains(t, w.String(), "P-123", "value should not be in clear")
+
+		decrypted, err := ioutil.ReadAll(NewDecryptReader(bytes.NewReader(w.Bytes()), map[string][]byte{"k1": key}))
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(
+			t,
+			`{"level":"info","message":"hello","patientID":"P-123\"456","foo":"bar"}`+"\n"+
+				`{"level":"info","message":"hello","params":{"account":{"number":"FR76 3000"}}}`+"\n",
+			string(decrypted),
+			"decrypted stream dont equal expected result",
+		)
+	})
+	t.Run("unknown-key-and-moved-value", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Encrypter(encrypter)
+		logger.InfoWithFields("hello", func(e Entry) {
+			e.Encrypted("a", "secret")
+		})
+		line := w.String()
+		moved := strings.Replace(line, `"a":`, `"b":`, 1)
+		decrypted, err := ioutil.ReadAll(NewDecryptReader(strings.NewReader(line+moved+"not json\n"), map[string][]byte{"k2": key}))
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, line+moved+"not json\n", string(decrypted), "values should be left untouched with an unknown key")
+		decrypted, err = ioutil.ReadAll(NewDecryptReader(strings.NewReader(moved), map[string][]byte{"k1": key}))
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, moved, string(decrypted), "moved values should not be decrypted")
+	})
+	t.Run("escaped-key", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Encrypter(encrypter)
+		logger.InfoWith("hello").Encrypted("patient\"ID\n", "P-123").Write()
+		decrypted, err := ioutil.ReadAll(NewDecryptReader(bytes.NewReader(w.Bytes()), map[string][]byte{"k1": key}))
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, `{"level":"info","message":"hello","patient\"ID\n":"P-123"}`+"\n", string(decrypted), "value with an escaped key should be decrypted")
+	})
+	t.Run("rand-failure", func(t *testing.T) {
+		defer func(r io.Reader) { randReader = r }(randReader)
+		randReader = iotest.ErrReader(errors.New("no entropy"))
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Encrypter(encrypter)
+		logger.InfoWith("hello").Encrypted("patientID", "P-123").Write()
+		assert.Equal(t, `{"level":"info","message":"hello","patientID":"[REDACTED]"}`+"\n", w.String(), "value should be redacted if it cannot be encrypted")
+	})
+	t.Run("no-encrypter", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL)
+		logger.InfoWith("hello").Encrypted("patientID", "P-123").Write()
+		assert.Equal(t, `{"level":"info","message":"hello","patientID":"[REDACTED]"}`+"\n", w.String(), "value should be redacted")
+	})
+	t.Run("nonces-differ", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Encrypter(encrypter)
+		logger.InfoWith("hello").Encrypted("a", "secret").Encrypted("a", "secret").Write()
+		values := regexp.MustCompile(`enc:k1:[^"]+`).FindAllString(w.String(), -1)
+		assert.Len(t, values, 2, "2 values should be encrypted")
+		assert.NotEqual(t, values[0], values[1], "ciphertexts should differ")
+	})
+	t.Run("invalid-key", func(t *testing.T) {
+		_, err := NewEncrypter("k1", []byte("short"))
+		assert.NotNil(t, err, "err should not be nil")
+	})
+}
@@ -1,6 +1,8 @@
 package onelog
 
 import (
+	"context"
+
 	"github.com/francoispqt/gojay"
 )
 
@@ -9,36 +11,49 @@ import (
 type Entry struct {
 	enc     *Encoder
 	l       *Logger
+	ctx     context.Context
 	Level   uint8
 	Message string
 }
 
 // String adds a string to the log entry.
 func (e Entry) String(k, v string) Entry {
-	e.enc.StringKey(k, v)
+	e.enc.StringKey(k, e.stringValue(k, v))
 	return e
 }
 
 // Int adds an int to the log entry.
 func (e Entry) Int(k string, v int) Entry {
+	if e.redactInt(k, int64(v)) {
+		return e
+	}
 	e.enc.IntKey(k, v)
 	return e
 }
 
 // Int64 adds an int64 to the log entry.
 func (e Entry) Int64(k string, v int64) Entry {
+	if e.redactInt(k, v) {
+		return e
+	}
 	e.enc.Int64Key(k, v)
 	return e
 }
 
 // Float adds a float64 to the log entry.
 func (e Entry) Float(k string, v float64) Entry {
+	if e.redactFloat(k, v, 64) {
+		return e
+	}
 	e.enc.FloatKey(k, v)
 	return e
 }
 
 // Bool adds a bool to the log entry.
 func (e Entry) Bool(k string, v bool) Entry {
+	if e.redact(k) {
+		return e
+	}
 	e.enc.BoolKey(k, v)
 	return e
 }
@@ -46,13 +61,16 @@ func (e Entry) Bool(k string, v bool) Entry {
 // Err adds an error to the log entry.
 func (e Entry) Err(k string, v error) Entry {
 	if v != nil {
-		e.enc.StringKey(k, v.Error())
+		e.errKey(k, v)
 	}
 	return e
 }
 
 // ObjectFunc adds an object to the log entry by calling a function.
 func (e Entry) ObjectFunc(k string, v func(Entry)) Entry {
+	if e.redact(k) {
+		return e
+	}
 	e.enc.ObjectKey(k, Object(func(enc *Encoder) {
 		v(e)
 	}))
@@ -61,16 +79,33 @@ func (e Entry) ObjectFunc(k string, v func(Entry)) Entry {
 
 // Object adds an object to the log entry by passing an implementation of gojay.MarshalerJSONObject.
 func (e Entry) Object(k string, obj gojay.MarshalerJSONObject) Entry {
+	if e.redact(k) {
+		return e
+	}
 	e.enc.ObjectKey(k, obj)
 	return e
 }
 
 // Array adds an object to the log entry by passing an implementation of gojay.MarshalerJSONObject.
 func (e Entry) Array(k string, obj gojay.MarshalerJSONArray) Entry {
+	if e.redact(k) {
+		return e
+	}
 	e.enc.ArrayKey(k, obj)
 	return e
 }
 
+// Encrypted adds a string encrypted with the logger's Encrypter to the log entry.
+// The value is written as "enc:<key ID>:<base64 nonce and ciphertext>",
+// or "[REDACTED]" if the logger has no Encrypter.
+func (e Entry) Encrypted(k, v string) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.enc.StringKey(k, e.encrypted(k, v))
+	return e
+}
+
 // ChainEntry is for chaining calls to the entry.
 type ChainEntry struct {
 	Entry
@@ -99,13 +134,13 @@ func (e ChainEntry) String(k, v string) ChainEntry {
 	if e.disabled {
 		return e
 	}
-	e.enc.StringKey(k, v)
+	e.enc.StringKey(k, e.stringValue(k, v))
 	return e
 }
 
 // Int adds an int to the log entry.
 func (e ChainEntry) Int(k string, v int) ChainEntry {
-	if e.disabled {
+	if e.disabled || e.redactInt(k, int64(v)) {
 		return e
 	}
 	e.enc.IntKey(k, v)
@@ -114,7 +149,7 @@ func (e ChainEntry) Int(k string, v int) ChainEntry {
 
 // Int64 adds an int64 to the log entry.
 func (e ChainEntry) Int64(k string, v int64) ChainEntry {
-	if e.disabled {
+	if e.disabled || e.redactInt(k, v) {
 		return e
 	}
 	e.enc.Int64Key(k, v)
@@ -123,7 +158,7 @@ func (e ChainEntry) Int64(k string, v int64) ChainEntry {
 
 // Float adds a float64 to the log entry.
 func (e ChainEntry) Float(k string, v float64) ChainEntry {
-	if e.disabled {
+	if e.disabled || e.redactFloat(k, v, 64) {
 		return e
 	}
 	e.enc.FloatKey(k, v)
@@ -132,7 +167,7 @@ func (e ChainEntry) Float(k string, v float64) ChainEntry {
 
 // Bool adds a bool to the log entry.
 func (e ChainEntry) Bool(k string, v bool) ChainEntry {
-	if e.disabled {
+	if e.disabled || e.redact(k) {
 		return e
 	}
 	e.enc.BoolKey(k, v)
@@ -145,14 +180,14 @@ func (e ChainEntry) Err(k string, v error) ChainEntry {
 		return e
 	}
 	if v != nil {
-		e.enc.StringKey(k, v.Error())
+		e.errKey(k, v)
 	}
 	return e
 }
 
 // ObjectFunc adds an object to the log entry by calling a function.
 func (e ChainEntry) ObjectFunc(k string, v func(Entry)) ChainEntry {
-	if e.disabled {
+	if e.disabled || e.redact(k) {
 		return e
 	}
 	e.enc.ObjectKey(k, Object(func(enc *Encoder) {
@@ -163,7 +198,7 @@ func (e ChainEntry) ObjectFunc(k string, v func(Entry)) ChainEntry {
 
 // Object adds an object to the log entry by passing an implementation of gojay.MarshalerJSONObject.
 func (e ChainEntry) Object(k string, obj gojay.MarshalerJSONObject) ChainEntry {
-	if e.disabled {
+	if e.disabled || e.redact(k) {
 		return e
 	}
 	e.enc.ObjectKey(k, obj)
@@ -172,18 +207,20 @@ func (e ChainEntry) Object(k string, obj gojay.MarshalerJSONObject) ChainEntry {
 
 // Array adds an object to the log entry by passing an implementation of gojay.MarshalerJSONObject.
 func (e ChainEntry) Array(k string, obj gojay.MarshalerJSONArray) ChainEntry {
-	if e.disabled {
+	if e.disabled || e.redact(k) {
 		return e
 	}
 	e.enc.ArrayKey(k, obj)
 	return e
 }
 
-// Any adds anything stuff to the log entry based on it's type
-func (e ChainEntry) Any(k string, obj interface{}) ChainEntry {
-	if e.disabled {
+// Encrypted adds a string encrypted with the logger's Encrypter to the log entry.
+// The value is written as "enc:<key ID>:<base64 nonce and ciphertext>",
+// or "[REDACTED]" if the logger has no Encrypter.
+func (e ChainEntry) Encrypted(k, v string) ChainEntry {
+	if e.disabled || e.redact(k) {
 		return e
 	}
-	e.enc.AddInterfaceKey(k, obj)
+	e.enc.StringKey(k, e.encrypted(k, v))
 	return e
 }
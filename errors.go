@@ -0,0 +1,133 @@
+package onelog
+
+import (
+	"reflect"
+)
+
+// maxCauses is the maximum number of causes encoded for an error, it protects against cyclic chains.
+const maxCauses = 32
+
+// ErrorMode is the way errors are encoded.
+type ErrorMode uint8
+
+const (
+	// ErrorString encodes errors as the string returned by their Error method.
+	ErrorString ErrorMode = iota
+	// ErrorRich encodes wrapped errors and errors implementing LogMarshaler as objects with
+	// a "message" field, a "type" field holding the Go type name, a "causes" array and their own fields.
+	// Other errors are encoded as strings.
+	ErrorRich
+)
+
+// LogMarshaler is implemented by errors adding structured fields to their rich encoding.
+type LogMarshaler interface {
+	LogObject(Entry)
+}
+
+// ErrorMode sets the way the logger encodes errors and returns it.
+// Loggers derived with With and WithContext inherit the mode.
+func (l *Logger) ErrorMode(mode ErrorMode) *Logger {
+	l.errorMode = mode
+	return l
+}
+
+// richError reports whether the error v must be encoded as an object by the entry's logger.
+func (e Entry) richError(v error) bool {
+	if e.l == nil || e.l.errorMode != ErrorRich {
+		return false
+	}
+	switch v.(type) {
+	case LogMarshaler, interface{ Unwrap() error }, interface{ Unwrap() []error }:
+		return true
+	}
+	return false
+}
+
+// errKey adds the error v to the log entry according to the logger's error mode.
+func (e Entry) errKey(k string, v error) {
+	if !e.richError(v) {
+		e.enc.StringKey(k, e.stringValue(k, v.Error()))
+		return
+	}
+	if e.redact(k) {
+		return
+	}
+	e.key(k)
+	e.errObject(v, maxCauses)
+}
+
+// errElem adds the error v to the array being encoded according to the logger's error mode, k being the array key.
+func (e Entry) errElem(k string, v error) {
+	if !e.richError(v) {
+		e.enc.AddString(e.stringValue(k, v.Error()))
+		return
+	}
+	e.elem()
+	e.errObject(v, maxCauses)
+}
+
+// errObject writes the error v as an object followed, at most n levels deep, by its causes.
+func (e Entry) errObject(v error, n int) {
+	e.enc.AppendByte('{')
+	e.errFields(v)
+	if errs := unwrapMulti(v); errs != nil {
+		e.errCauses(errs, n)
+	} else if cause := unwrap(v); cause != nil && n > 0 {
+		e.key("causes")
+		e.enc.AppendByte('[')
+		// single causes are flattened, the walk only branches on multi errors.
+		for ; cause != nil && n > 0; n-- {
+			e.elem()
+			if errs := unwrapMulti(cause); errs != nil {
+				e.errObject(cause, n-1)
+				break
+			}
+			e.enc.AppendByte('{')
+			e.errFields(cause)
+			e.enc.AppendByte('}')
+			cause = unwrap(cause)
+		}
+		e.enc.AppendByte(']')
+	}
+	e.enc.AppendByte('}')
+}
+
+// errCauses writes the errors joined in a multi error as its causes.
+func (e Entry) errCauses(errs []error, n int) {
+	if n <= 0 {
+		return
+	}
+	e.key("causes")
+	e.enc.AppendByte('[')
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		e.elem()
+		e.errObject(err, n-1)
+	}
+	e.enc.AppendByte(']')
+}
+
+// errFields writes the message, the type and the fields of the error v.
+func (e Entry) errFields(v error) {
+	e.String("message", v.Error())
+	e.enc.StringKey("type", reflect.TypeOf(v).String())
+	if m, ok := v.(LogMarshaler); ok {
+		m.LogObject(e)
+	}
+}
+
+func unwrap(v error) error {
+	if u, ok := v.(interface{ Unwrap() error }); ok {
+		return u.Unwrap()
+	}
+	return nil
+}
+
+func unwrapMulti(v error) []error {
+	if u, ok := v.(interface{ Unwrap() []error }); ok {
+		return u.Unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package onelog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testFieldsError struct {
+	code int
+}
+
+func (e *testFieldsError) Error() string { return "not found" }
+
+func (e *testFieldsError) LogObject(entry Entry) {
+	entry.Int("code", e.code)
+}
+
+func TestErrorMode(t *testing.T) {
+	base := &testFieldsError{code: 404}
+	wrapped := fmt.Errorf("get user: %w", fmt.Errorf("query: %w", base))
+	t.Run("string-mode", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO)
+		logger.InfoWith("hello").Err("err", wrapped).Write()
+		assert.Equal(t, `{"level":"info","message":"hello","err":"get user: query: not found"}`+"\n", string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("rich-wrapped", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO).ErrorMode(ErrorRich)
+		logger.InfoWith("hello").Err("err", wrapped).Write()
+		json := `{"level":"info","message":"hello","err":{"message":"get user: query: not found","type":"*fmt.wrapError",` +
+			`"causes":[{"message":"query: not found","type":"*fmt.wrapError"},` +
+			`{"message":"not found","type":"*onelog.testFieldsError","code":404}]}}` + "\n"
+		assert.Equal(t, json, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("rich-plain", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO).ErrorMode(ErrorRich)
+		logger.InfoWithFields("hello", func(e Entry) {
+			e.Err("err", errors.New("boom")).Err("fields", base)
+		})
+		json := `{"level":"info","message":"hello","err":"boom","fields":{"message":"not found","type":"*onelog.testFieldsError","code":404}}` + "\n"
+		assert.Equal(t, json, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("rich-joined", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO).ErrorMode(ErrorRich)
+		joined := fmt.Errorf("batch: %w", errors.Join(errors.New("a"), fmt.Errorf("b: %w", errors.New("c"))))
+		logger.InfoWith("hello").Err("err", joined).Write()
+		json := `{"level":"info","message":"hello","err":{"message":"batch: a\nb: c","type":"*fmt.wrapError",` +
+			`"causes":[{"message":"a\nb: c","type":"*errors.joinError","causes":[` +
+			`{"message":"a","type":"*errors.errorString"},` +
+			`{"message":"b: c","type":"*fmt.wrapError","causes":[{"message":"c","type":"*errors.errorString"}]}]}]}}` + "\n"
+		assert.Equal(t, json, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("rich-array", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO).ErrorMode(ErrorRich)
+		logger.InfoWith("hello").
+			Errs("errs", []error{errors.New("a"), base, nil}).
+			ArrayFunc("arr", func(a ArrayEntry) {
+				a.Err(base).Err(errors.New("b"))
+			}).
+			Write()
+		json := `{"level":"info","message":"hello","errs":["a",{"message":"not found","type":"*onelog.testFieldsError","code":404},null],` +
+			`"arr":[{"message":"not found","type":"*onelog.testFieldsError","code":404},"b"]}` + "\n"
+		assert.Equal(t, json, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("rich-inherited-and-redacted", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO).ErrorMode(ErrorRich).Redactor(NewRedactor().Keys("secret").Detect(Email))
+		logger.With(func(e Entry) {}).InfoWith("hello").
+			Any("secret", wrapped).
+			Any("err", fmt.Errorf("send: %w", errors.New("foo@example.com"))).
+			Write()
+		json := `{"level":"info","message":"hello","secret":"[REDACTED]","err":{"message":"send: foo@example.com","type":"*fmt.wrapError",` +
+			`"causes":[{"message":"[REDACTED]","type":"*errors.errorString"}]}}` + "\n"
+		assert.Equal(t, json, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("cyclic", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, INFO).ErrorMode(ErrorRich)
+		logger.InfoWith("hello").Err("err", cyclicError{}).Write()
+		assert.Contains(t, string(w.b), `"causes":[{"message":"cycle","type":"onelog.cyclicError"}`)
+		assert.Equal(t, maxCauses, strings.Count(string(w.b), `"message":"cycle"`)-1)
+	})
+}
+
+type cyclicError struct{}
+
+func (cyclicError) Error() string { return "cycle" }
+
+func (e cyclicError) Unwrap() error { return e }
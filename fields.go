@@ -0,0 +1,464 @@
+package onelog
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// chunkSize is the size of the input chunks encoded to the stack by Bytes and Hex,
+// it is a multiple of 3 so base64 chunks can be concatenated.
+const chunkSize = 48
+
+// key appends the key k to the entry, preceded by a comma if needed.
+// It must be followed by a raw value appended with e.enc.AppendBytes.
+func (e Entry) key(k string) {
+	if b := e.enc.Buf(); len(b) > 0 && b[len(b)-1] != '{' {
+		e.enc.AppendByte(',')
+	}
+	e.enc.AppendString(k)
+	e.enc.AppendByte(':')
+}
+
+// elem appends a comma before an array element if needed.
+func (e Entry) elem() {
+	if b := e.enc.Buf(); len(b) > 0 && b[len(b)-1] != '[' {
+		e.enc.AppendByte(',')
+	}
+}
+
+// Uint adds an uint to the log entry.
+func (e Entry) Uint(k string, v uint) Entry {
+	if e.redactUint(k, uint64(v)) {
+		return e
+	}
+	e.enc.Uint64Key(k, uint64(v))
+	return e
+}
+
+// Uint64 adds an uint64 to the log entry.
+func (e Entry) Uint64(k string, v uint64) Entry {
+	if e.redactUint(k, v) {
+		return e
+	}
+	e.enc.Uint64Key(k, v)
+	return e
+}
+
+// Int8 adds an int8 to the log entry.
+func (e Entry) Int8(k string, v int8) Entry {
+	if e.redactInt(k, int64(v)) {
+		return e
+	}
+	e.enc.Int8Key(k, v)
+	return e
+}
+
+// Int16 adds an int16 to the log entry.
+func (e Entry) Int16(k string, v int16) Entry {
+	if e.redactInt(k, int64(v)) {
+		return e
+	}
+	e.enc.Int16Key(k, v)
+	return e
+}
+
+// Int32 adds an int32 to the log entry.
+func (e Entry) Int32(k string, v int32) Entry {
+	if e.redactInt(k, int64(v)) {
+		return e
+	}
+	e.enc.Int32Key(k, v)
+	return e
+}
+
+// Float32 adds a float32 to the log entry.
+func (e Entry) Float32(k string, v float32) Entry {
+	if e.redactFloat(k, float64(v), 32) {
+		return e
+	}
+	e.enc.Float32Key(k, v)
+	return e
+}
+
+// Time adds a time formatted with the given layout to the log entry.
+func (e Entry) Time(k string, v time.Time, format string) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.enc.TimeKey(k, &v, format)
+	return e
+}
+
+// Duration adds a duration as an integer number of nanoseconds to the log entry.
+func (e Entry) Duration(k string, v time.Duration) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.enc.Int64Key(k, int64(v))
+	return e
+}
+
+// DurationMs adds a duration as a floating number of milliseconds to the log entry.
+func (e Entry) DurationMs(k string, v time.Duration) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.enc.FloatKey(k, float64(v)/float64(time.Millisecond))
+	return e
+}
+
+// DurationString adds a duration formatted like "1m30.5s" to the log entry.
+func (e Entry) DurationString(k string, v time.Duration) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.enc.StringKey(k, v.String())
+	return e
+}
+
+// Bytes adds a byte slice encoded in standard base64 to the log entry.
+func (e Entry) Bytes(k string, v []byte) Entry {
+	if e.redact(k) {
+		return e
+	}
+	var buf [chunkSize * 4 / 3]byte
+	cut := 0
+	if max := e.l.maxString(); max > 0 && base64.StdEncoding.EncodedLen(len(v)) > max {
+		cut = len(v) - max/4*3
+		v = v[:max/4*3]
+	}
+	e.key(k)
+	e.enc.AppendByte('"')
+	for len(v) > 0 {
+		n := len(v)
+		if n > chunkSize {
+			n = chunkSize
+		}
+		base64.StdEncoding.Encode(buf[:], v[:n])
+		e.enc.AppendBytes(buf[:base64.StdEncoding.EncodedLen(n)])
+		v = v[n:]
+	}
+	if cut > 0 {
+		e.enc.AppendBytes(appendTruncated(buf[:0], cut))
+	}
+	e.enc.AppendByte('"')
+	return e
+}
+
+// Hex adds a byte slice encoded in hexadecimal to the log entry.
+func (e Entry) Hex(k string, v []byte) Entry {
+	if e.redact(k) {
+		return e
+	}
+	var buf [chunkSize * 2]byte
+	cut := 0
+	if max := e.l.maxString(); max > 0 && hex.EncodedLen(len(v)) > max {
+		cut = len(v) - max/2
+		v = v[:max/2]
+	}
+	e.key(k)
+	e.enc.AppendByte('"')
+	for len(v) > 0 {
+		n := len(v)
+		if n > chunkSize {
+			n = chunkSize
+		}
+		hex.Encode(buf[:], v[:n])
+		e.enc.AppendBytes(buf[:n*2])
+		v = v[n:]
+	}
+	if cut > 0 {
+		e.enc.AppendBytes(appendTruncated(buf[:0], cut))
+	}
+	e.enc.AppendByte('"')
+	return e
+}
+
+// Stringer adds the result of calling String on v to the log entry, or null if v is nil.
+func (e Entry) Stringer(k string, v fmt.Stringer) Entry {
+	if e.redact(k) {
+		return e
+	}
+	if v == nil || isNilPointer(v) {
+		e.enc.AddNullKey(k)
+		return e
+	}
+	e.enc.StringKey(k, e.stringValue(k, v.String()))
+	return e
+}
+
+// isNilPointer reports whether v holds a nil pointer, whose methods would dereference it.
+func isNilPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// IP adds an IP address to the log entry, or null if the address is invalid.
+func (e Entry) IP(k string, v net.IP) Entry {
+	if e.redact(k) {
+		return e
+	}
+	addr, ok := netip.AddrFromSlice(v)
+	if !ok {
+		e.enc.AddNullKey(k)
+		return e
+	}
+	var buf [64]byte
+	b := append(buf[:0], '"')
+	b = addr.Unmap().AppendTo(b)
+	b = append(b, '"')
+	e.key(k)
+	e.enc.AppendBytes(b)
+	return e
+}
+
+// IPNet adds an IP network in CIDR notation to the log entry, or null if v is nil.
+func (e Entry) IPNet(k string, v *net.IPNet) Entry {
+	if e.redact(k) {
+		return e
+	}
+	if v == nil {
+		e.enc.AddNullKey(k)
+		return e
+	}
+	addr, ok := netip.AddrFromSlice(v.IP)
+	ones, bits := v.Mask.Size()
+	if !ok || bits == 0 {
+		// non canonical mask, let the standard library format it.
+		e.enc.StringKey(k, v.String())
+		return e
+	}
+	addr = addr.Unmap()
+	if addr.Is4() && bits == 128 {
+		ones -= 96
+	}
+	var buf [64]byte
+	b := append(buf[:0], '"')
+	b = addr.AppendTo(b)
+	b = append(b, '/')
+	b = strconv.AppendInt(b, int64(ones), 10)
+	b = append(b, '"')
+	e.key(k)
+	e.enc.AppendBytes(b)
+	return e
+}
+
+// HardwareAddr adds a hardware address like "00:00:5e:00:53:01" to the log entry.
+func (e Entry) HardwareAddr(k string, v net.HardwareAddr) Entry {
+	if e.redact(k) {
+		return e
+	}
+	const hexDigit = "0123456789abcdef"
+	var buf [64]byte
+	b := append(buf[:0], '"')
+	for i, c := range v {
+		if i > 0 {
+			b = append(b, ':')
+		}
+		b = append(b, hexDigit[c>>4], hexDigit[c&0xf])
+	}
+	b = append(b, '"')
+	e.key(k)
+	e.enc.AppendBytes(b)
+	return e
+}
+
+// URL adds an URL to the log entry, or null if v is nil.
+// The password of the URL, if any, is redacted.
+func (e Entry) URL(k string, v *url.URL) Entry {
+	if e.redact(k) {
+		return e
+	}
+	if v == nil {
+		e.enc.AddNullKey(k)
+		return e
+	}
+	e.enc.StringKey(k, e.stringValue(k, v.Redacted()))
+	return e
+}
+
+// RawJSON adds raw JSON bytes to the log entry, they must be valid JSON.
+func (e Entry) RawJSON(k string, v []byte) Entry {
+	if e.redact(k) {
+		return e
+	}
+	e.key(k)
+	if len(v) == 0 {
+		e.enc.AppendBytes(nullBytes)
+		return e
+	}
+	e.enc.AppendBytes(v)
+	return e
+}
+
+var nullBytes = []byte("null")
+
+// Uint adds an uint to the log entry.
+func (e ChainEntry) Uint(k string, v uint) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Uint(k, v)
+	return e
+}
+
+// Uint64 adds an uint64 to the log entry.
+func (e ChainEntry) Uint64(k string, v uint64) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Uint64(k, v)
+	return e
+}
+
+// Int8 adds an int8 to the log entry.
+func (e ChainEntry) Int8(k string, v int8) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Int8(k, v)
+	return e
+}
+
+// Int16 adds an int16 to the log entry.
+func (e ChainEntry) Int16(k string, v int16) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Int16(k, v)
+	return e
+}
+
+// Int32 adds an int32 to the log entry.
+func (e ChainEntry) Int32(k string, v int32) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Int32(k, v)
+	return e
+}
+
+// Float32 adds a float32 to the log entry.
+func (e ChainEntry) Float32(k string, v float32) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Float32(k, v)
+	return e
+}
+
+// Time adds a time formatted with the given layout to the log entry.
+func (e ChainEntry) Time(k string, v time.Time, format string) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Time(k, v, format)
+	return e
+}
+
+// Duration adds a duration as an integer number of nanoseconds to the log entry.
+func (e ChainEntry) Duration(k string, v time.Duration) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Duration(k, v)
+	return e
+}
+
+// DurationMs adds a duration as a floating number of milliseconds to the log entry.
+func (e ChainEntry) DurationMs(k string, v time.Duration) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.DurationMs(k, v)
+	return e
+}
+
+// DurationString adds a duration formatted like "1m30.5s" to the log entry.
+func (e ChainEntry) DurationString(k string, v time.Duration) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.DurationString(k, v)
+	return e
+}
+
+// Bytes adds a byte slice encoded in standard base64 to the log entry.
+func (e ChainEntry) Bytes(k string, v []byte) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Bytes(k, v)
+	return e
+}
+
+// Hex adds a byte slice encoded in hexadecimal to the log entry.
+func (e ChainEntry) Hex(k string, v []byte) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Hex(k, v)
+	return e
+}
+
+// Stringer adds the result of calling String on v to the log entry, or null if v is nil.
+func (e ChainEntry) Stringer(k string, v fmt.Stringer) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.Stringer(k, v)
+	return e
+}
+
+// IP adds an IP address to the log entry, or null if the address is invalid.
+func (e ChainEntry) IP(k string, v net.IP) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.IP(k, v)
+	return e
+}
+
+// IPNet adds an IP network in CIDR notation to the log entry, or null if v is nil.
+func (e ChainEntry) IPNet(k string, v *net.IPNet) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.IPNet(k, v)
+	return e
+}
+
+// HardwareAddr adds a hardware address like "00:00:5e:00:53:01" to the log entry.
+func (e ChainEntry) HardwareAddr(k string, v net.HardwareAddr) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.HardwareAddr(k, v)
+	return e
+}
+
+// URL adds an URL to the log entry, or null if v is nil.
+// The password of the URL, if any, is redacted.
+func (e ChainEntry) URL(k string, v *url.URL) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.URL(k, v)
+	return e
+}
+
+// RawJSON adds raw JSON bytes to the log entry, they must be valid JSON.
+func (e ChainEntry) RawJSON(k string, v []byte) ChainEntry {
+	if e.disabled {
+		return e
+	}
+	e.Entry.RawJSON(k, v)
+	return e
+}
@@ -0,0 +1,206 @@
+// Package httplog provides a net/http middleware logging requests with onelog.
+package httplog
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/francoispqt/onelog"
+)
+
+// DefaultRequestIDHeader is the default header holding request IDs.
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// Middleware logs an entry for each request and stores a request scoped logger in the request context.
+//
+// The entry has the "method", "path", "status", "bytes", "duration_ms", "remote_ip", "user_agent"
+// and "request_id" fields. It is logged with ERROR for 5xx statuses, WARN for 4xx statuses and INFO otherwise.
+//
+// The entry is also logged when the handler panics, with a 500 status if no status was written,
+// so a Recovery can wrap the middleware or be wrapped by it. Wrapping the Recovery in the middleware
+// gives its panic entry the request scoped fields.
+type Middleware struct {
+	l          *onelog.Logger
+	header     string
+	skip       []func(*http.Request) bool
+	trustProxy bool
+	now        func() time.Time
+}
+
+// New returns a Middleware logging with l.
+func New(l *onelog.Logger) *Middleware {
+	return &Middleware{
+		l:      l,
+		header: DefaultRequestIDHeader,
+		now:    time.Now,
+	}
+}
+
+// RequestIDHeader sets the header holding request IDs.
+// Requests without ID get a random one, which is also set in the response header.
+func (m *Middleware) RequestIDHeader(header string) *Middleware {
+	m.header = header
+	return m
+}
+
+// Skip adds a rule skipping the access log of requests for which f returns true.
+// Skipped requests still get a request scoped logger.
+func (m *Middleware) Skip(f func(*http.Request) bool) *Middleware {
+	m.skip = append(m.skip, f)
+	return m
+}
+
+// SkipPaths skips the access log of requests for the given paths, like health checks.
+func (m *Middleware) SkipPaths(paths ...string) *Middleware {
+	return m.Skip(func(r *http.Request) bool {
+		for _, p := range paths {
+			if r.URL.Path == p {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// TrustProxy makes the remote IP be read from the X-Forwarded-For and X-Real-Ip headers when present.
+// Only enable it behind a proxy setting these headers.
+func (m *Middleware) TrustProxy() *Middleware {
+	m.trustProxy = true
+	return m
+}
+
+// Handler returns a handler calling next and logging the request.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := m.now()
+		id := r.Header.Get(m.header)
+		if id == "" {
+			id = newRequestID()
+			w.Header().Set(m.header, id)
+		}
+		rl := m.l.Ctx(r.Context()).With(func(e onelog.Entry) {
+			e.String("request_id", id)
+		})
+		r = r.WithContext(onelog.WithLogger(r.Context(), rl))
+
+		rw := &responseWriter{ResponseWriter: w}
+		// the access log is deferred so requests whose handler panics are logged
+		// when the panic is recovered by a Recovery wrapping the middleware.
+		done := false
+		defer func() {
+			for _, skip := range m.skip {
+				if skip(r) {
+					return
+				}
+			}
+			if rw.status == 0 {
+				rw.status = http.StatusOK
+				if !done {
+					rw.status = http.StatusInternalServerError
+				}
+			}
+			fields := func(e onelog.Entry) {
+				e.String("method", r.Method).
+					String("path", r.URL.Path).
+					Int("status", rw.status).
+					Int64("bytes", rw.bytes).
+					DurationMs("duration_ms", m.now().Sub(start)).
+					String("remote_ip", m.remoteIP(r)).
+					String("user_agent", r.UserAgent())
+			}
+			switch {
+			case rw.status >= 500:
+				rl.ErrorWithFields("request", fields)
+			case rw.status >= 400:
+				rl.WarnWithFields("request", fields)
+			default:
+				rl.InfoWithFields("request", fields)
+			}
+		}()
+		next.ServeHTTP(rw, r)
+		done = true
+	})
+}
+
+func (m *Middleware) remoteIP(r *http.Request) string {
+	if m.trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			if i := strings.IndexByte(fwd, ','); i >= 0 {
+				fwd = fwd[:i]
+			}
+			return strings.TrimSpace(fwd)
+		}
+		if ip := r.Header.Get("X-Real-Ip"); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// responseWriter records the status and the number of bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying writer does.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker if the underlying writer does, so websocket upgrades work through the middleware.
+// Requests whose connection is hijacked before a status is written are logged with status 101.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("httplog: %T does not implement http.Hijacker", w.ResponseWriter)
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
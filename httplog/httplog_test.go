@@ -0,0 +1,149 @@
+package httplog
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/francoispqt/onelog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMiddleware(w *bytes.Buffer) *Middleware {
+	m := New(onelog.New(w, onelog.ALL))
+	start := time.Date(2018, 12, 20, 9, 31, 23, 0, time.UTC)
+	calls := 0
+	m.now = func() time.Time {
+		calls++
+		return start.Add(time.Duration(calls-1) * 1500 * time.Microsecond)
+	}
+	return m
+}
+
+func TestMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		onelog.FromContext(r.Context()).Info("handling")
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/fail":
+			http.Error(w, "fail", http.StatusInternalServerError)
+		default:
+			w.Write([]byte("hello"))
+		}
+	})
+	t.Run("ok", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := newTestMiddleware(w).Handler(handler)
+		req := httptest.NewRequest("GET", "/users?id=1", nil)
+		req.Header.Set("X-Request-Id", "req-1")
+		req.Header.Set("User-Agent", "test")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		json := `{"level":"info","message":"handling","request_id":"req-1"}` + "\n" +
+			`{"level":"info","message":"request","request_id":"req-1","method":"GET","path":"/users","status":200,` +
+			`"bytes":5,"duration_ms":1.5,"remote_ip":"192.0.2.1","user_agent":"test"}` + "\n"
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+		assert.Equal(t, "", rec.Header().Get("X-Request-Id"), "existing request IDs should not be set in the response")
+	})
+	t.Run("levels", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := newTestMiddleware(w).Handler(handler)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+		assert.Contains(t, w.String(), `{"level":"warn","message":"request"`)
+		assert.Contains(t, w.String(), `"status":404,"bytes":0`)
+		w.Reset()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/fail", nil))
+		assert.Contains(t, w.String(), `{"level":"error","message":"request"`)
+		assert.Contains(t, w.String(), `"method":"POST","path":"/fail","status":500`)
+	})
+	t.Run("generated-request-id", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := newTestMiddleware(w).RequestIDHeader("X-Trace").Handler(handler)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		id := rec.Header().Get("X-Trace")
+		assert.Len(t, id, 32)
+		assert.Contains(t, w.String(), `"request_id":"`+id+`"`)
+	})
+	t.Run("skip", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := newTestMiddleware(w).
+			SkipPaths("/healthz").
+			Skip(func(r *http.Request) bool { return r.Method == http.MethodOptions }).
+			Handler(handler)
+		req := httptest.NewRequest("GET", "/healthz", nil)
+		req.Header.Set("X-Request-Id", "req-1")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("OPTIONS", "/", nil))
+		assert.Contains(t, w.String(), `{"level":"info","message":"handling","request_id":"req-1"}`+"\n", "skipped requests should get a request scoped logger")
+		assert.NotContains(t, w.String(), `"message":"request"`)
+	})
+	t.Run("trust-proxy", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := newTestMiddleware(w).TrustProxy().Handler(handler)
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Contains(t, w.String(), `"remote_ip":"203.0.113.7"`)
+
+		w.Reset()
+		req = httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		newTestMiddleware(w).Handler(handler).ServeHTTP(httptest.NewRecorder(), req)
+		assert.Contains(t, w.String(), `"remote_ip":"192.0.2.1"`, "forwarded headers should be ignored by default")
+	})
+	t.Run("extractors", func(t *testing.T) {
+		type tenantKey struct{}
+		w := &bytes.Buffer{}
+		m := newTestMiddleware(w)
+		m.l.Extractors(onelog.ContextValue(tenantKey{}, "tenant"))
+		h := m.Handler(handler)
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-Id", "req-1")
+		req = req.WithContext(context.WithValue(req.Context(), tenantKey{}, "acme"))
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Contains(t, w.String(), `{"level":"info","message":"handling","tenant":"acme","request_id":"req-1"}`)
+	})
+	t.Run("flush", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := newTestMiddleware(w).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, http.NewResponseController(w).Flush())
+		}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		assert.True(t, rec.Flushed)
+		assert.Contains(t, w.String(), `"status":200`)
+	})
+	t.Run("hijack", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := newTestMiddleware(w).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, _, err := http.NewResponseController(w).Hijack()
+			if assert.NoError(t, err) {
+				conn.Close()
+			}
+		}))
+		done := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer close(done)
+			h.ServeHTTP(w, r)
+		}))
+		defer srv.Close()
+		_, err := http.Get(srv.URL)
+		assert.Error(t, err, "connection should have been closed by the handler")
+		<-done
+		assert.Contains(t, w.String(), `"status":101`)
+	})
+	t.Run("hijack-not-supported", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := newTestMiddleware(w).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _, err := w.(http.Hijacker).Hijack()
+			assert.Error(t, err, "recorder cannot be hijacked")
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		assert.Contains(t, w.String(), `"status":200`)
+	})
+}
@@ -0,0 +1,56 @@
+package httplog
+
+import (
+	"net/http"
+
+	"github.com/francoispqt/onelog"
+)
+
+// Recovery recovers panics of HTTP handlers and logs them with onelog.LogPanic,
+// with the "method" and "path" fields of the request.
+//
+// Panics are logged with the request scoped logger stored in the request context,
+// like the one set by Middleware, or with the logger of the Recovery otherwise.
+// http.ErrAbortHandler panics are not logged and always re-panicked.
+type Recovery struct {
+	l       *onelog.Logger
+	repanic bool
+}
+
+// NewRecovery returns a Recovery logging with l the panics of requests without request scoped logger.
+func NewRecovery(l *onelog.Logger) *Recovery {
+	return &Recovery{l: l}
+}
+
+// Repanic makes the recovery re-panic after logging instead of replying with a 500 status.
+func (rc *Recovery) Repanic() *Recovery {
+	rc.repanic = true
+	return rc
+}
+
+// Handler returns a handler calling next and recovering its panics.
+func (rc *Recovery) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			l := rc.l.Ctx(r.Context())
+			if onelog.HasLogger(r.Context()) {
+				l = onelog.FromContext(r.Context())
+			}
+			onelog.LogPanic(l, v, func(e onelog.Entry) {
+				e.String("method", r.Method).String("path", r.URL.Path)
+			})
+			if rc.repanic {
+				panic(v)
+			}
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
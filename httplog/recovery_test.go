@@ -0,0 +1,72 @@
+package httplog
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/francoispqt/onelog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecovery(t *testing.T) {
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	t.Run("500", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := NewRecovery(onelog.New(w, onelog.ALL)).Handler(panicking)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/users", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.True(t, strings.HasPrefix(w.String(), `{"level":"error","message":"panic","panic":"boom","stack":"goroutine `), w.String())
+		assert.True(t, strings.HasSuffix(w.String(), `,"method":"GET","path":"/users"}`+"\n"), w.String())
+	})
+	t.Run("request-scoped-logger", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := onelog.New(w, onelog.ALL)
+		h := newTestMiddleware(w).Handler(NewRecovery(logger).Handler(panicking))
+		req := httptest.NewRequest("GET", "/users", nil)
+		req.Header.Set("X-Request-Id", "req-1")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		lines := strings.Split(strings.TrimSuffix(w.String(), "\n"), "\n")
+		assert.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[0], `{"level":"error","message":"panic","request_id":"req-1","panic":"boom"`), lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], `{"level":"error","message":"request","request_id":"req-1","method":"GET","path":"/users","status":500`), lines[1])
+	})
+	t.Run("wrapping-middleware", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := onelog.New(w, onelog.ALL)
+		h := NewRecovery(logger).Handler(newTestMiddleware(w).Handler(panicking))
+		req := httptest.NewRequest("GET", "/users", nil)
+		req.Header.Set("X-Request-Id", "req-1")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		lines := strings.Split(strings.TrimSuffix(w.String(), "\n"), "\n")
+		assert.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[0], `{"level":"error","message":"request","request_id":"req-1","method":"GET","path":"/users","status":500`), lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], `{"level":"error","message":"panic","panic":"boom"`), lines[1])
+	})
+	t.Run("repanic", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := NewRecovery(onelog.New(w, onelog.ALL)).Repanic().Handler(panicking)
+		assert.PanicsWithValue(t, "boom", func() {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		})
+		assert.Contains(t, w.String(), `"panic":"boom"`)
+	})
+	t.Run("abort-handler", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := NewRecovery(onelog.New(w, onelog.ALL)).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+		assert.Panics(t, func() {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		})
+		assert.Equal(t, ``, w.String())
+	})
+}
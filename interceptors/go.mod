@@ -0,0 +1,23 @@
+module github.com/francoispqt/onelog/interceptors
+
+go 1.23.0
+
+require (
+	github.com/francoispqt/onelog v0.0.0-20261019094926-8bee3db7d5aa
+	github.com/stretchr/testify v1.2.2
+	google.golang.org/grpc v1.75.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/francoispqt/gojay v0.0.0-20181220093123-f2cc13a668ca // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.41.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.26.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
+)
+
+// the core module is taken from the working tree for local development.
+replace github.com/francoispqt/onelog => ../
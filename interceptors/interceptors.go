@@ -0,0 +1,130 @@
+// Package interceptors provides gRPC server interceptors logging calls with onelog.
+//
+// It is a separate module so the onelog core does not depend on gRPC.
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/francoispqt/onelog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptor logs an entry for each gRPC call and stores a call scoped logger,
+// holding the "method" field, in the call context.
+//
+// The entry has the "method", "code", "duration_ms" and "peer" fields, and the "error" field for failed calls.
+// Its level is chosen from the status code with DefaultLevel unless configured with Levels.
+type Interceptor struct {
+	l      *onelog.Logger
+	levels func(codes.Code) uint8
+	skip   map[string]bool
+	now    func() time.Time
+}
+
+// New returns an Interceptor logging with l.
+func New(l *onelog.Logger) *Interceptor {
+	return &Interceptor{
+		l:      l,
+		levels: DefaultLevel,
+		skip:   map[string]bool{},
+		now:    time.Now,
+	}
+}
+
+// DefaultLevel returns INFO for OK, ERROR for server side failures and WARN for client side failures.
+func DefaultLevel(code codes.Code) uint8 {
+	switch code {
+	case codes.OK:
+		return onelog.INFO
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return onelog.ERROR
+	default:
+		return onelog.WARN
+	}
+}
+
+// Levels sets the function returning the level of a call from its status code.
+func (i *Interceptor) Levels(f func(codes.Code) uint8) *Interceptor {
+	i.levels = f
+	return i
+}
+
+// SkipMethods skips the log of calls to the given full methods, like "/grpc.health.v1.Health/Check".
+// Skipped calls still get a call scoped logger.
+func (i *Interceptor) SkipMethods(methods ...string) *Interceptor {
+	for _, m := range methods {
+		i.skip[m] = true
+	}
+	return i
+}
+
+// Unary returns a unary server interceptor.
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := i.now()
+		ctx, l := i.logger(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		i.log(ctx, l, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// Stream returns a stream server interceptor.
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := i.now()
+		ctx, l := i.logger(ss.Context(), info.FullMethod)
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		i.log(ctx, l, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// logger returns the call scoped logger and a context holding it.
+func (i *Interceptor) logger(ctx context.Context, method string) (context.Context, *onelog.Logger) {
+	l := i.l.Ctx(ctx).With(func(e onelog.Entry) {
+		e.String("method", method)
+	})
+	return onelog.WithLogger(ctx, l), l
+}
+
+func (i *Interceptor) log(ctx context.Context, l *onelog.Logger, method string, start time.Time, err error) {
+	if i.skip[method] {
+		return
+	}
+	code := status.Code(err)
+	fields := func(e onelog.Entry) {
+		e.String("code", code.String()).DurationMs("duration_ms", i.now().Sub(start))
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			e.String("peer", p.Addr.String())
+		}
+		if err != nil {
+			e.Err("error", err)
+		}
+	}
+	switch i.levels(code) {
+	case onelog.DEBUG:
+		l.DebugWithFields("rpc", fields)
+	case onelog.INFO:
+		l.InfoWithFields("rpc", fields)
+	case onelog.WARN:
+		l.WarnWithFields("rpc", fields)
+	default:
+		l.ErrorWithFields("rpc", fields)
+	}
+}
+
+// serverStream overrides the context of a stream with the one holding the call scoped logger.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,172 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/francoispqt/onelog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type syncWriter struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.b.Write(p)
+}
+
+func (w *syncWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.b.String()
+}
+
+// healthServer fails checks of the "bad" service and sends one response per watch.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	fromCtx bool
+}
+
+func (h *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	h.fromCtx = onelog.HasLogger(ctx)
+	onelog.FromContext(ctx).Info("check")
+	switch req.Service {
+	case "bad":
+		return nil, status.Error(codes.Internal, "boom")
+	case "missing":
+		return nil, status.Error(codes.NotFound, "no service")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func (h *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, ss grpc_health_v1.Health_WatchServer) error {
+	onelog.FromContext(ss.Context()).Info("watch")
+	return ss.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+}
+
+func newTestClient(t *testing.T, i *Interceptor, h *healthServer) grpc_health_v1.HealthClient {
+	lis := bufconn.Listen(1 << 16)
+	s := grpc.NewServer(grpc.UnaryInterceptor(i.Unary()), grpc.StreamInterceptor(i.Stream()))
+	grpc_health_v1.RegisterHealthServer(s, h)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func newTestInterceptor(w *syncWriter) *Interceptor {
+	i := New(onelog.New(w, onelog.ALL))
+	i.now = func() time.Time { return time.Unix(0, 0) }
+	return i
+}
+
+func TestUnary(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		w := &syncWriter{}
+		h := &healthServer{}
+		c := newTestClient(t, newTestInterceptor(w), h)
+		_, err := c.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		assert.Nil(t, err, "err should be nil")
+		assert.True(t, h.fromCtx, "context should hold a logger")
+		lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+		assert.Len(t, lines, 2, "two entries should be written")
+		assert.Equal(t, `{"level":"info","message":"check","method":"/grpc.health.v1.Health/Check"}`, lines[0], "handler entry should have the method field")
+		assert.True(t, strings.HasPrefix(lines[1], `{"level":"info","message":"rpc","method":"/grpc.health.v1.Health/Check","code":"OK","duration_ms":0,"peer":"bufconn"}`), "rpc entry should be written, got: %s", lines[1])
+	})
+	t.Run("error", func(t *testing.T) {
+		w := &syncWriter{}
+		c := newTestClient(t, newTestInterceptor(w), &healthServer{})
+		_, err := c.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "bad"})
+		assert.Equal(t, codes.Internal, status.Code(err), "code should be Internal")
+		assert.Contains(t, w.String(), `{"level":"error","message":"rpc","method":"/grpc.health.v1.Health/Check","code":"Internal","duration_ms":0,"peer":"bufconn","error":"rpc error: code = Internal desc = boom"}`, "rpc entry should be an error")
+	})
+	t.Run("client-error", func(t *testing.T) {
+		w := &syncWriter{}
+		c := newTestClient(t, newTestInterceptor(w), &healthServer{})
+		_, err := c.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "missing"})
+		assert.Equal(t, codes.NotFound, status.Code(err), "code should be NotFound")
+		assert.Contains(t, w.String(), `{"level":"warn","message":"rpc","method":"/grpc.health.v1.Health/Check","code":"NotFound"`, "rpc entry should be a warning")
+	})
+	t.Run("levels", func(t *testing.T) {
+		w := &syncWriter{}
+		i := newTestInterceptor(w).Levels(func(codes.Code) uint8 { return onelog.DEBUG })
+		c := newTestClient(t, i, &healthServer{})
+		_, err := c.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "bad"})
+		assert.NotNil(t, err, "err should not be nil")
+		assert.Contains(t, w.String(), `{"level":"debug","message":"rpc"`, "rpc entry should have the configured level")
+	})
+	t.Run("skip", func(t *testing.T) {
+		w := &syncWriter{}
+		h := &healthServer{}
+		c := newTestClient(t, newTestInterceptor(w).SkipMethods("/grpc.health.v1.Health/Check"), h)
+		_, err := c.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		assert.Nil(t, err, "err should be nil")
+		assert.True(t, h.fromCtx, "context should hold a logger")
+		assert.NotContains(t, w.String(), `"message":"rpc"`, "rpc entry should not be written")
+	})
+	t.Run("extractors", func(t *testing.T) {
+		type key struct{}
+		w := &syncWriter{}
+		l := onelog.New(w, onelog.ALL).Extractors(onelog.ContextValue(key{}, "tenant"))
+		i := New(l)
+		i.now = func() time.Time { return time.Unix(0, 0) }
+		_, err := i.Unary()(
+			context.WithValue(context.Background(), key{}, "acme"),
+			nil,
+			&grpc.UnaryServerInfo{FullMethod: "/svc/M"},
+			func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil },
+		)
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, `{"level":"info","message":"rpc","tenant":"acme","method":"/svc/M","code":"OK","duration_ms":0}`+"\n", w.String(), "entry should have the extracted fields")
+	})
+}
+
+func TestStream(t *testing.T) {
+	w := &syncWriter{}
+	c := newTestClient(t, newTestInterceptor(w), &healthServer{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := c.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	assert.Nil(t, err, "err should be nil")
+	_, err = stream.Recv()
+	assert.Nil(t, err, "err should be nil")
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	assert.Len(t, lines, 2, "two entries should be written")
+	assert.Equal(t, `{"level":"info","message":"watch","method":"/grpc.health.v1.Health/Watch"}`, lines[0], "handler entry should have the method field")
+	assert.Equal(t, `{"level":"info","message":"rpc","method":"/grpc.health.v1.Health/Watch","code":"OK","duration_ms":0,"peer":"bufconn"}`, lines[1], "rpc entry should be written")
+}
+
+func TestDefaultLevel(t *testing.T) {
+	assert.Equal(t, onelog.INFO, DefaultLevel(codes.OK), "OK should be INFO")
+	assert.Equal(t, onelog.WARN, DefaultLevel(codes.InvalidArgument), "InvalidArgument should be WARN")
+	assert.Equal(t, onelog.WARN, DefaultLevel(codes.Canceled), "Canceled should be WARN")
+	assert.Equal(t, onelog.ERROR, DefaultLevel(codes.Unavailable), "Unavailable should be ERROR")
+	assert.Equal(t, onelog.ERROR, DefaultLevel(codes.Unknown), "Unknown should be ERROR")
+}
@@ -0,0 +1,372 @@
+package onelog
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// Limits configures the maximum size of log entries.
+// A zero value disables the corresponding limit.
+//
+// Truncation keeps entries valid JSON: truncated strings end with a marker like "…(truncated 39MB)",
+// arrays end with a marker element, objects nested too deep are replaced with a marker
+// and fields which do not fit in MaxBytes are cut or dropped. The level is always kept and the message
+// is cut last, entries whose level alone does not fit in MaxBytes are dropped.
+// Truncated entries get a "_truncated":true field when it fits.
+type Limits struct {
+	// MaxString is the maximum length in bytes of string values, before JSON escaping.
+	// Strings, including the message, and byte slices are cut while they are encoded,
+	// strings inside values encoded with encoding/json by Any are not.
+	MaxString int
+	// MaxArray is the maximum number of elements of arrays.
+	MaxArray int
+	// MaxDepth is the maximum nesting depth of objects and arrays, fields of the entry being at depth 1.
+	MaxDepth int
+	// MaxBytes is the maximum length in bytes of an entry, including the trailing new line.
+	MaxBytes int
+}
+
+const (
+	truncatedMarker = "…(truncated"
+	ellipsis        = "…"
+	// maxMarkerLen is the maximum length of markers, used to reserve space for them.
+	maxMarkerLen = len(truncatedMarker) + len(" 1023.9GB)") + 2
+	// unlimited is the budget of entries without MaxBytes.
+	unlimited = int(^uint(0) >> 1)
+)
+
+var (
+	truncatedField       = []byte(`"_truncated":true`)
+	truncatedMarkerBytes = []byte(truncatedMarker)
+)
+
+var truncaterPool = sync.Pool{
+	New: func() interface{} {
+		return &truncater{}
+	},
+}
+
+type truncater struct {
+	lim       *Limits
+	out       []byte
+	truncated bool
+}
+
+// Limits sets the size limits of the logger's entries and returns it.
+// The limits are shared by loggers derived with With and WithContext.
+func (l *Logger) Limits(lim Limits) *Logger {
+	l.limits = &lim
+	return l
+}
+
+// apply calls f with the entry truncated to the limits.
+// Strings have already been cut to MaxString while encoding, apply only looks for their marker
+// to flag the entry as truncated. Entries which cannot fit in MaxBytes are dropped.
+func (lim *Limits) apply(b []byte, f func([]byte)) {
+	cut := lim.MaxString > 0 && bytes.Contains(b, truncatedMarkerBytes)
+	extra := 0
+	if cut {
+		extra = 1 + len(truncatedField)
+	}
+	fast := lim.MaxArray == 0 && lim.MaxDepth == 0 && (lim.MaxBytes == 0 || len(b)+extra <= lim.MaxBytes)
+	if fast && !cut {
+		f(b)
+		return
+	}
+	t := truncaterPool.Get().(*truncater)
+	t.lim = lim
+	t.out = t.out[:0]
+	t.truncated = cut
+
+	line := b
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		line = line[:len(line)-1]
+	}
+	max := unlimited
+	if lim.MaxBytes > 0 {
+		max = lim.MaxBytes - 1
+	}
+	if fast && len(line) > 0 && line[len(line)-1] == '}' {
+		t.out = append(t.out, line...)
+	} else if !t.root(line, max) {
+		// malformed entries are written untouched if they fit, entries whose level does not fit are dropped.
+		if lim.MaxBytes == 0 || len(b) <= lim.MaxBytes {
+			f(b)
+		}
+		truncaterPool.Put(t)
+		return
+	}
+	if t.truncated && len(t.out)+1+len(truncatedField) <= max {
+		t.out = t.out[:len(t.out)-1]
+		if t.out[len(t.out)-1] != '{' {
+			t.out = append(t.out, ',')
+		}
+		t.out = append(t.out, truncatedField...)
+		t.out = append(t.out, '}')
+	}
+	t.out = append(t.out, '\n')
+	f(t.out)
+	truncaterPool.Put(t)
+}
+
+// root writes the entry's object within max bytes. The first member, the level, is kept whole
+// or the entry is dropped, the message is cut before the "_truncated" field and the other fields
+// are cut or dropped to leave room for both.
+// It reports false if the entry is malformed or its level does not fit.
+func (t *truncater) root(v []byte, max int) bool {
+	if max < 2 {
+		return false
+	}
+	reserve := 1 + len(truncatedField)
+	t.out = append(t.out, '{')
+	i, level := 0, true
+	ok := eachMember(v, func(k []byte, s, e int) bool {
+		mark := len(t.out)
+		if i > 0 {
+			t.out = append(t.out, ',')
+		}
+		t.out = append(t.out, '"')
+		t.out = append(t.out, k...)
+		t.out = append(t.out, '"', ':')
+		// keep room for the closing brace.
+		budget := max - len(t.out) - 1
+		i++
+		switch {
+		case i == 1:
+			if e-s > budget {
+				level = false
+				return false
+			}
+			t.out = append(t.out, v[s:e]...)
+			return true
+		case i == 2 && string(k) == msgKey:
+			if t.value(v[s:e], 1, budget-reserve) || t.value(v[s:e], 1, budget) {
+				return true
+			}
+		default:
+			if t.value(v[s:e], 1, budget-reserve) {
+				return true
+			}
+		}
+		t.out = t.out[:mark]
+		t.truncated = true
+		return true
+	})
+	if !ok || !level || i == 0 {
+		return false
+	}
+	t.out = append(t.out, '}')
+	return true
+}
+
+// value writes the JSON value v within budget bytes and reports whether it has been written.
+func (t *truncater) value(v []byte, depth, budget int) bool {
+	switch v[0] {
+	case '"':
+		return t.string(v, budget)
+	case '{', '[':
+		if t.lim.MaxDepth > 0 && depth > t.lim.MaxDepth {
+			if len(truncatedMarker)+3 > budget {
+				return false
+			}
+			t.truncated = true
+			t.out = append(t.out, '"')
+			t.out = append(t.out, truncatedMarker...)
+			t.out = append(t.out, ")\""...)
+			return true
+		}
+		if v[0] == '{' {
+			return t.object(v, depth, budget)
+		}
+		return t.array(v, depth, budget)
+	default:
+		if len(v) > budget {
+			return false
+		}
+		t.out = append(t.out, v...)
+		return true
+	}
+}
+
+func (t *truncater) string(v []byte, budget int) bool {
+	if len(v) <= budget {
+		t.out = append(t.out, v...)
+		return true
+	}
+	content := v[1 : len(v)-1]
+	n := budget - 2 - maxMarkerLen
+	short := n < 0
+	if short {
+		// no room for the size, only keep the ellipsis.
+		n = budget - 2 - len(ellipsis)
+	}
+	if n < 0 {
+		return false
+	}
+	n = cutString(content, n)
+	t.truncated = true
+	t.out = append(t.out, '"')
+	t.out = append(t.out, content[:n]...)
+	if short {
+		t.out = append(t.out, ellipsis...)
+	} else {
+		t.out = appendTruncated(t.out, len(content)-n)
+	}
+	t.out = append(t.out, '"')
+	return true
+}
+
+func (t *truncater) object(v []byte, depth, budget int) bool {
+	if budget < 2 {
+		return false
+	}
+	start := len(t.out)
+	t.out = append(t.out, '{')
+	budget -= 2
+	ok := eachMember(v, func(k []byte, s, e int) bool {
+		mark := len(t.out)
+		if t.out[len(t.out)-1] != '{' {
+			t.out = append(t.out, ',')
+		}
+		t.out = append(t.out, '"')
+		t.out = append(t.out, k...)
+		t.out = append(t.out, '"', ':')
+		used := len(t.out) - mark
+		if used > budget || !t.value(v[s:e], depth+1, budget-used) {
+			t.out = t.out[:mark]
+			t.truncated = true
+			return true
+		}
+		budget -= len(t.out) - mark
+		return true
+	})
+	if !ok {
+		t.out = t.out[:start]
+		return false
+	}
+	t.out = append(t.out, '}')
+	return true
+}
+
+func (t *truncater) array(v []byte, depth, budget int) bool {
+	if budget < 2 {
+		return false
+	}
+	start := len(t.out)
+	t.out = append(t.out, '[')
+	budget -= 2
+	reserve := 1 + maxMarkerLen
+	n, dropped := 0, 0
+	ok := eachElement(v, func(s, e int) bool {
+		if dropped > 0 || (t.lim.MaxArray > 0 && n >= t.lim.MaxArray) {
+			dropped++
+			return true
+		}
+		mark := len(t.out)
+		if n > 0 {
+			t.out = append(t.out, ',')
+		}
+		used := len(t.out) - mark
+		if used+reserve > budget || !t.value(v[s:e], depth+1, budget-used-reserve) {
+			t.out = t.out[:mark]
+			dropped++
+			return true
+		}
+		budget -= len(t.out) - mark
+		n++
+		return true
+	})
+	if !ok {
+		t.out = t.out[:start]
+		return false
+	}
+	if dropped > 0 {
+		t.truncated = true
+		if n > 0 {
+			t.out = append(t.out, ',')
+		}
+		t.out = append(t.out, '"')
+		t.out = append(t.out, truncatedMarker...)
+		t.out = append(t.out, ' ')
+		t.out = strconv.AppendInt(t.out, int64(dropped), 10)
+		t.out = append(t.out, " elements)\""...)
+	}
+	t.out = append(t.out, ']')
+	return true
+}
+
+// cutString returns the largest length lower or equal to n at which the JSON string content s
+// can be cut without breaking an escape sequence or a UTF-8 character.
+func cutString(s []byte, n int) int {
+	i := 0
+	for i < n {
+		size := 1
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == 'u':
+			size = 6
+		case s[i] == '\\':
+			size = 2
+		case s[i] >= utf8.RuneSelf:
+			_, size = utf8.DecodeRune(s[i:])
+		}
+		if i+size > n {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// appendTruncated appends the marker of a string cut by n bytes to b.
+func appendTruncated(b []byte, n int) []byte {
+	b = append(b, truncatedMarker...)
+	b = append(b, ' ')
+	b = appendSize(b, n)
+	return append(b, ')')
+}
+
+// maxString returns the MaxString limit of the logger, 0 if strings are not limited.
+func (l *Logger) maxString() int {
+	if l == nil || l.limits == nil {
+		return 0
+	}
+	return l.limits.MaxString
+}
+
+// limitString returns v cut to the MaxString limit with a truncation marker.
+func (l *Logger) limitString(v string) string {
+	n := l.maxString()
+	if n <= 0 || len(v) <= n {
+		return v
+	}
+	for n > 0 && !utf8.RuneStart(v[n]) {
+		n--
+	}
+	b := make([]byte, 0, n+maxMarkerLen)
+	b = append(b, v[:n]...)
+	return string(appendTruncated(b, len(v)-n))
+}
+
+// appendSize appends a human readable size to b.
+func appendSize(b []byte, n int) []byte {
+	const unit = 1024
+	if n < unit {
+		b = strconv.AppendInt(b, int64(n), 10)
+		return append(b, 'B')
+	}
+	units := "KMGT"
+	f := float64(n) / unit
+	i := 0
+	for f >= unit && i < len(units)-1 {
+		f /= unit
+		i++
+	}
+	prec := 1
+	if f >= 10 {
+		prec = 0
+	}
+	b = strconv.AppendFloat(b, f, 'f', prec, 64)
+	return append(b, units[i], 'B')
+}
@@ -0,0 +1,129 @@
+package onelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits(t *testing.T) {
+	t.Run("max-string", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Limits(Limits{MaxString: 5})
+		logger.InfoWith("hello world").
+			String("body", strings.Repeat("a", 2048)).
+			String("short", "abc").
+			Write()
+		assert.Equal(
+			t,
+			`{"level":"info","message":"hello…(truncated 6B)","body":"aaaaa…(truncated 2.0KB)","short":"abc","_truncated":true}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+	})
+	t.Run("max-string-escapes-and-utf8", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Limits(Limits{MaxString: 4})
+		logger.InfoWith("hi").
+			String("escape", "ab\"cd").
+			String("unicode", "aébcd").
+			String("control", "a\x01bcd").
+			Write()
+		assert.Equal(
+			t,
+			`{"level":"info","message":"hi","escape":"ab\"c…(truncated 1B)","unicode":"aéb…(truncated 2B)","control":"a\u0001bc…(truncated 1B)","_truncated":true}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+	})
+	t.Run("max-string-bytes", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Limits(Limits{MaxString: 8})
+		logger.InfoWith("hi").
+			Bytes("b64", []byte("abcdefghij")).
+			Hex("hex", []byte("abcdefghij")).
+			Any("any", []byte("abc")).
+			Write()
+		assert.Equal(
+			t,
+			`{"level":"info","message":"hi","b64":"YWJjZGVm…(truncated 4B)","hex":"61626364…(truncated 6B)","any":"YWJj","_truncated":true}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+	})
+	t.Run("max-array-and-depth", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Limits(Limits{MaxArray: 2, MaxDepth: 1})
+		testObj := &TestObj{"bar"}
+		logger.InfoWith("hello").
+			Array("arr", TestObjArr{testObj, testObj, testObj}).
+			ObjectFunc("a", func(e Entry) {
+				e.ObjectFunc("b", func(e Entry) {
+					e.Int("c", 1)
+				})
+			}).
+			Write()
+		assert.Equal(
+			t,
+			`{"level":"info","message":"hello","arr":["…(truncated)","…(truncated)","…(truncated 1 elements)"],"a":{"b":"…(truncated)"},"_truncated":true}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+	})
+	t.Run("max-bytes", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := NewContext(w, ALL, "params").Limits(Limits{MaxBytes: 256})
+		logger.InfoWithFields("hello", func(e Entry) {
+			e.Int("status", 500)
+			e.Bool("retry", false)
+			e.String("body", strings.Repeat("a", 40*1024*1024))
+		})
+		assert.True(t, w.Len() <= 256, "entry should be at most 256 bytes")
+		var decoded map[string]interface{}
+		assert.Nil(t, json.Unmarshal(w.Bytes(), &decoded), "entry should be valid JSON")
+		assert.Equal(t, true, decoded["_truncated"], "entry should be flagged as truncated")
+		params := decoded["params"].(map[string]interface{})
+		assert.Equal(t, float64(500), params["status"], "status should be kept")
+		assert.Equal(t, false, params["retry"], "retry should be kept")
+		assert.True(t, strings.HasSuffix(params["body"].(string), "…(truncated 40MB)"), "body should be truncated")
+	})
+	t.Run("max-bytes-message", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Limits(Limits{MaxBytes: 40})
+		logger.InfoWith(strings.Repeat("a", 100)).Int("status", 500).Write()
+		assert.Equal(t, `{"level":"info","message":"aaaaaaa…"}`+"\n", w.String(), "message should be kept and cut")
+		assert.True(t, w.Len() <= 40, "entry should be at most 40 bytes")
+	})
+	t.Run("max-bytes-truncated-field", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Limits(Limits{MaxBytes: 64})
+		logger.InfoWith("hello").String("body", strings.Repeat("a", 100)).Write()
+		assert.Equal(t, `{"level":"info","message":"hello","_truncated":true}`+"\n", w.String(), "body should be dropped")
+	})
+	t.Run("max-bytes-too-small", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Limits(Limits{MaxBytes: 15})
+		logger.InfoWith("hello world").String("foo", "bar").Write()
+		logger.Info("")
+		assert.Equal(t, "", w.String(), "entries which cannot fit should be dropped")
+	})
+	t.Run("untouched", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Limits(Limits{MaxString: 10, MaxArray: 10, MaxDepth: 10, MaxBytes: 1024})
+		testObj := &TestObj{"bar"}
+		logger.InfoWith("hello").
+			Array("arr", TestObjArr{testObj}).
+			String("foo", "bar").
+			Write()
+		assert.Equal(t, `{"level":"info","message":"hello","arr":[{"foo":"bar"}],"foo":"bar"}`+"\n", w.String(), "bytes written to the writer dont equal expected result")
+	})
+}
+
+func TestAppendSize(t *testing.T) {
+	assert.Equal(t, "12B", string(appendSize(nil, 12)), "bytes")
+	assert.Equal(t, "1.5KB", string(appendSize(nil, 1536)), "kilobytes")
+	assert.Equal(t, "39MB", string(appendSize(nil, 40*1024*1024-1024*1024+1)), "megabytes")
+}
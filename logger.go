@@ -52,6 +52,15 @@ type Logger struct {
 	ctx         []func(Entry)
 	ExitFn      ExitFunc
 	contextName string
+	sampler     *Sampler
+	limiter     *RateLimiter
+	dedup       *Deduper
+	buffer      *scopeBuffer
+	redactor    *Redactor
+	limits      *Limits
+	encrypter   *Encrypter
+	errorMode   ErrorMode
+	extractors  []Extractor
 }
 
 // New returns a fresh onelog Logger with default values.
@@ -95,6 +104,15 @@ func (l *Logger) copy(ctxName string) *Logger {
 		hook:        l.hook,
 		contextName: ctxName,
 		ExitFn:      l.ExitFn,
+		sampler:     l.sampler,
+		limiter:     l.limiter,
+		dedup:       l.dedup,
+		buffer:      l.buffer,
+		redactor:    l.redactor,
+		limits:      l.limits,
+		encrypter:   l.encrypter,
+		errorMode:   l.errorMode,
+		extractors:  l.extractors,
 	}
 	if len(l.ctx) > 0 {
 		var ctx = make([]func(e Entry), len(l.ctx))
@@ -116,6 +134,32 @@ func (l *Logger) With(f func(Entry)) *Logger {
 	return nL
 }
 
+// WithStatic copies the current Logger and adds it the fields added by func f,
+// which are encoded once and copied into each entry.
+// Use With for fields whose values must be computed for each entry.
+func (l *Logger) WithStatic(f func(Entry)) *Logger {
+	nL := l.copy(l.contextName)
+
+	enc := gojay.BorrowEncoder(nil)
+	enc.AppendBytes(logOpen)
+	f(Entry{enc: enc, l: nL})
+	if len(enc.Buf()) == len(logOpen) {
+		enc.Release()
+		return nL
+	}
+	fields := make([]byte, len(enc.Buf())-len(logOpen))
+	copy(fields, enc.Buf()[len(logOpen):])
+	enc.Release()
+
+	nL.ctx = append(nL.ctx, func(e Entry) {
+		if b := e.enc.Buf(); b[len(b)-1] != '{' {
+			e.enc.AppendByte(',')
+		}
+		e.enc.AppendBytes(fields)
+	})
+	return nL
+}
+
 // WithContext copies current logger enforcing all entry fields to be
 // set into a map with the contextName set as the key name for giving map.
 // This allows allocating all future uses of the logging methods to
@@ -130,10 +174,10 @@ func (l *Logger) WithContext(contextName string) *Logger {
 func (l *Logger) Info(msg string) {
 	// first find writer for level
 	// if none, stop
-	if INFO&l.levels == 0 {
+	if INFO&l.levels == 0 || l.drop(INFO, msg) {
 		return
 	}
-	e := Entry{Level: INFO, Message: msg}
+	e := Entry{l: l, Level: INFO, Message: msg}
 
 	enc := gojay.BorrowEncoder(l.w)
 	e.enc = enc
@@ -164,7 +208,7 @@ func (l *Logger) InfoWith(msg string) ChainEntry {
 			Message: msg,
 		},
 	}
-	e.disabled = INFO&e.l.levels == 0
+	e.disabled = INFO&e.l.levels == 0 || l.drop(INFO, msg)
 	if e.disabled {
 		return e
 	}
@@ -187,10 +231,10 @@ func (l *Logger) InfoWith(msg string) ChainEntry {
 func (l *Logger) InfoWithFields(msg string, fields func(Entry)) {
 	// first find writer for level
 	// if none, stop
-	if INFO&l.levels == 0 {
+	if INFO&l.levels == 0 || l.drop(INFO, msg) {
 		return
 	}
-	e := Entry{Level: INFO, Message: msg}
+	e := Entry{l: l, Level: INFO, Message: msg}
 
 	e.enc = gojay.BorrowEncoder(l.w)
 
@@ -214,10 +258,10 @@ func (l *Logger) InfoWithFields(msg string, fields func(Entry)) {
 func (l *Logger) Debug(msg string) {
 	// check if level is in config
 	// if not, return
-	if DEBUG&l.levels == 0 {
+	if DEBUG&l.levels == 0 || l.drop(DEBUG, msg) {
 		return
 	}
-	e := Entry{Level: DEBUG, Message: msg}
+	e := Entry{l: l, Level: DEBUG, Message: msg}
 
 	e.enc = gojay.BorrowEncoder(l.w)
 
@@ -247,7 +291,7 @@ func (l *Logger) DebugWith(msg string) ChainEntry {
 			Message: msg,
 		},
 	}
-	e.disabled = DEBUG&e.l.levels == 0
+	e.disabled = DEBUG&e.l.levels == 0 || l.drop(DEBUG, msg)
 	if e.disabled {
 		return e
 	}
@@ -270,10 +314,10 @@ func (l *Logger) DebugWith(msg string) ChainEntry {
 func (l *Logger) DebugWithFields(msg string, fields func(Entry)) {
 	// check if level is in config
 	// if not, return
-	if DEBUG&l.levels == 0 {
+	if DEBUG&l.levels == 0 || l.drop(DEBUG, msg) {
 		return
 	}
-	e := Entry{Level: DEBUG, Message: msg}
+	e := Entry{l: l, Level: DEBUG, Message: msg}
 
 	e.enc = gojay.BorrowEncoder(l.w)
 
@@ -297,10 +341,10 @@ func (l *Logger) DebugWithFields(msg string, fields func(Entry)) {
 func (l *Logger) Warn(msg string) {
 	// check if level is in config
 	// if not, return
-	if WARN&l.levels == 0 {
+	if WARN&l.levels == 0 || l.drop(WARN, msg) {
 		return
 	}
-	e := Entry{Level: WARN, Message: msg}
+	e := Entry{l: l, Level: WARN, Message: msg}
 
 	e.enc = gojay.BorrowEncoder(l.w)
 
@@ -330,7 +374,7 @@ func (l *Logger) WarnWith(msg string) ChainEntry {
 			Message: msg,
 		},
 	}
-	e.disabled = WARN&e.l.levels == 0
+	e.disabled = WARN&e.l.levels == 0 || l.drop(WARN, msg)
 	if e.disabled {
 		return e
 	}
@@ -351,10 +395,11 @@ func (l *Logger) WarnWith(msg string) ChainEntry {
 
 // WarnWithFields logs an entry with WARN level and custom fields.
 func (l *Logger) WarnWithFields(msg string, fields func(Entry)) {
-	if WARN&l.levels == 0 {
+	if WARN&l.levels == 0 || l.drop(WARN, msg) {
 		return
 	}
 	e := Entry{
+		l:       l,
 		Level:   WARN,
 		Message: msg,
 	}
@@ -379,10 +424,11 @@ func (l *Logger) WarnWithFields(msg string, fields func(Entry)) {
 
 // Error logs an entry with ERROR level
 func (l *Logger) Error(msg string) {
-	if ERROR&l.levels == 0 {
+	if ERROR&l.levels == 0 || l.drop(ERROR, msg) {
 		return
 	}
 	e := Entry{
+		l:       l,
 		Level:   ERROR,
 		Message: msg,
 	}
@@ -415,7 +461,7 @@ func (l *Logger) ErrorWith(msg string) ChainEntry {
 			Message: msg,
 		},
 	}
-	e.disabled = ERROR&e.l.levels == 0
+	e.disabled = ERROR&e.l.levels == 0 || l.drop(ERROR, msg)
 	if e.disabled {
 		return e
 	}
@@ -436,10 +482,11 @@ func (l *Logger) ErrorWith(msg string) ChainEntry {
 
 // ErrorWithFields logs an entry with ERROR level and custom fields.
 func (l *Logger) ErrorWithFields(msg string, fields func(Entry)) {
-	if ERROR&l.levels == 0 {
+	if ERROR&l.levels == 0 || l.drop(ERROR, msg) {
 		return
 	}
 	e := Entry{
+		l:       l,
 		Level:   ERROR,
 		Message: msg,
 	}
@@ -467,6 +514,7 @@ func (l *Logger) Fatal(msg string) {
 		return
 	}
 	e := Entry{
+		l:       l,
 		Level:   FATAL,
 		Message: msg,
 	}
@@ -505,6 +553,7 @@ func (l *Logger) FatalWith(msg string) ChainEntry {
 	if e.disabled {
 		return e
 	}
+	e.exit = true
 
 	e.Entry.enc = gojay.BorrowEncoder(l.w)
 
@@ -517,7 +566,6 @@ func (l *Logger) FatalWith(msg string) ChainEntry {
 	}
 
 	l.openEntry(e.Entry.enc)
-	e.exit = true
 	return e
 }
 
@@ -528,6 +576,7 @@ func (l *Logger) FatalWithFields(msg string, fields func(Entry)) {
 	}
 
 	e := Entry{
+		l:       l,
 		Level:   FATAL,
 		Message: msg,
 	}
@@ -557,12 +606,13 @@ func (l *Logger) openEntry(enc *Encoder) {
 
 func (l *Logger) beginEntry(level uint8, msg string, e Entry) {
 	e.enc.AppendBytes(levelsJSON[level])
-	e.enc.AppendString(msg)
+	e.enc.AppendString(l.limitString(msg))
 
-	if l.ctx != nil && l.contextName == "" {
+	if l.contextName == "" {
 		for _, c := range l.ctx {
 			c(e)
 		}
+		l.extract(e)
 	}
 }
 
@@ -573,6 +623,25 @@ func (l Logger) runHook(e Entry) {
 	l.hook(e)
 }
 
+// writeInternal writes an entry generated by the logger itself, such as summaries.
+// It bypasses the sampler and the scope buffer, so it is not discarded with the scope's entries,
+// and ignores the logger's context, only the hook is run.
+func (l *Logger) writeInternal(level uint8, msg string, fields func(Entry)) {
+	e := Entry{l: l, Level: level, Message: msg}
+	e.enc = gojay.BorrowEncoder(l.w)
+	e.enc.AppendBytes(levelsJSON[level])
+	e.enc.AppendString(l.limitString(msg))
+	l.runHook(e)
+	fields(e)
+	e.enc.AppendBytes(logClose)
+	if l.limits != nil {
+		l.limits.apply(e.enc.Buf(), l.output)
+	} else {
+		l.output(e.enc.Buf())
+	}
+	e.enc.Release()
+}
+
 func (l *Logger) finalizeIfContext(entry Entry) {
 	if l.contextName == "" {
 		return
@@ -599,7 +668,7 @@ func (l *Logger) finalizeIfContext(entry Entry) {
 
 	// we need to manually write output as logger
 	// has context.
-	entryEnc.Write()
+	l.write(entry.Level, entryEnc)
 }
 
 func (l *Logger) closeEntry(e Entry) {
@@ -611,14 +680,42 @@ func (l *Logger) closeEntry(e Entry) {
 				c(e)
 			}
 		}
+		l.extract(e)
 		e.enc.AppendBytes(logCloseOnly)
 	}
 
 	if l.contextName == "" {
-		e.enc.Write()
+		l.write(e.Level, e.enc)
 	}
 }
 
+// write writes the encoded entry to the logger's writer,
+// truncated to the limits unless it is held by the scope buffer.
+func (l *Logger) write(level uint8, enc *Encoder) {
+	if l.limits != nil {
+		l.limits.apply(enc.Buf(), func(b []byte) {
+			l.writeBytes(level, b)
+		})
+		return
+	}
+	l.writeBytes(level, enc.Buf())
+}
+
+func (l *Logger) writeBytes(level uint8, b []byte) {
+	if l.buffer != nil && l.buffer.hold(l, level, b) {
+		return
+	}
+	l.output(b)
+}
+
+func (l *Logger) output(b []byte) {
+	if l.dedup != nil {
+		l.dedup.write(b)
+		return
+	}
+	l.w.Write(b)
+}
+
 func (l *Logger) exit(code int) {
 	if l.ExitFn == nil {
 		// fallback to os.Exit to prevent panic incase set as nil.
@@ -627,6 +724,11 @@ func (l *Logger) exit(code int) {
 	l.ExitFn(code)
 }
 
+// Enabled reports whether the logger has any of the levels in the mask enabled.
+func (l *Logger) Enabled(levels uint8) bool {
+	return l.levels&levels != 0
+}
+
 // Caller returns the caller in the stack trace, skipped n times.
 func (l *Logger) Caller(n int) string {
 	_, f, fl, _ := runtime.Caller(n)
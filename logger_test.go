@@ -1280,3 +1280,60 @@ func TestFatalActualOsExit(t *testing.T) {
 	}
 	t.Fatalf("process ran with err %v, want exit status 1", err)
 }
+
+func TestOnelogWithStatic(t *testing.T) {
+	t.Run("no-context", func(t *testing.T) {
+		w := &strings.Builder{}
+		calls := 0
+		logger := New(w, ALL).
+			WithStatic(func(e Entry) {
+				calls++
+				e.String("service", "api").Int("version", 2)
+			}).
+			With(func(e Entry) { e.Int("dynamic", 1) }).
+			WithStatic(func(e Entry) { e.Bool("static", true) })
+		logger.InfoWith("message").String("userID", "123456").Write()
+		logger.Info("message")
+		json := `{"level":"info","message":"message","service":"api","version":2,"dynamic":1,"static":true,"userID":"123456"}` + "\n" +
+			`{"level":"info","message":"message","service":"api","version":2,"dynamic":1,"static":true}` + "\n"
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+		assert.Equal(t, 1, calls, "static fields should be encoded once")
+	})
+	t.Run("context", func(t *testing.T) {
+		w := newWriter()
+		logger := NewContext(w, ALL, "params").
+			WithStatic(func(e Entry) { e.String("service", "api") })
+		logger.InfoWithFields("message", func(e Entry) {
+			e.String("userID", "123456")
+		})
+		json := `{"level":"info","message":"message","params":{"userID":"123456","service":"api"}}` + "\n"
+		assert.Equal(t, json, string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("empty", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, ALL).WithStatic(func(e Entry) {})
+		logger.Info("message")
+		assert.Equal(t, `{"level":"info","message":"message"}`+"\n", string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("parent-untouched", func(t *testing.T) {
+		w := newWriter()
+		parent := New(w, ALL).With(func(e Entry) { e.Int("parent", 1) })
+		parent.WithStatic(func(e Entry) { e.Int("child", 1) })
+		parent.Info("message")
+		assert.Equal(t, `{"level":"info","message":"message","parent":1}`+"\n", string(w.b), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("redacted", func(t *testing.T) {
+		w := newWriter()
+		logger := New(w, ALL).Redactor(NewRedactor().Keys("token")).
+			WithStatic(func(e Entry) { e.String("token", "secret") })
+		logger.Info("message")
+		assert.Equal(t, `{"level":"info","message":"message","token":"[REDACTED]"}`+"\n", string(w.b), "bytes written to the writer dont equal expected result")
+	})
+}
+
+func TestOnelogEnabled(t *testing.T) {
+	logger := New(nil, INFO|WARN)
+	assert.True(t, logger.Enabled(INFO), "INFO should be enabled")
+	assert.True(t, logger.Enabled(DEBUG|WARN), "mask with WARN should be enabled")
+	assert.False(t, logger.Enabled(DEBUG), "DEBUG should not be enabled")
+}
@@ -0,0 +1,18 @@
+module github.com/francoispqt/onelog/onelogr
+
+go 1.21
+
+require (
+	github.com/francoispqt/onelog v0.0.0-20261019094926-8bee3db7d5aa
+	github.com/go-logr/logr v1.4.3
+	github.com/stretchr/testify v1.2.2
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/francoispqt/gojay v0.0.0-20181220093123-f2cc13a668ca // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
+
+// the core module is taken from the working tree for local development.
+replace github.com/francoispqt/onelog => ../
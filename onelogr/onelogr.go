@@ -0,0 +1,130 @@
+// Package onelogr provides a logr.LogSink writing with onelog, so libraries logging with
+// github.com/go-logr/logr, like controller-runtime, share the onelog output and schema.
+//
+// It is a separate module so the onelog core does not depend on logr.
+package onelogr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/francoispqt/onelog"
+	"github.com/go-logr/logr"
+)
+
+// Sink is a logr.LogSink writing entries with a Logger.
+//
+// onelog has no level below DEBUG, so V(0) entries are logged with INFO and more verbose entries with DEBUG,
+// the verbosity being added as the "v" field. Errors are logged with ERROR and the error as the "error" field.
+// Values added with WithValues are encoded once like WithStatic fields,
+// and names added with WithName are joined with dots in the "logger" field.
+type Sink struct {
+	l         *onelog.Logger
+	name      string
+	verbosity int
+}
+
+var _ logr.LogSink = (*Sink)(nil)
+
+// New returns a logr.Logger writing with l.
+func New(l *onelog.Logger) logr.Logger {
+	return logr.New(NewSink(l))
+}
+
+// NewSink returns a Sink writing with l, with no verbosity limit.
+func NewSink(l *onelog.Logger) *Sink {
+	return &Sink{l: l, verbosity: -1}
+}
+
+// Verbosity sets the highest V-level logged, entries with a higher V-level are dropped.
+// A negative value removes the limit.
+func (s *Sink) Verbosity(v int) *Sink {
+	s.verbosity = v
+	return s
+}
+
+// Init does nothing, the logger's own Caller can be used to add the caller.
+func (s *Sink) Init(logr.RuntimeInfo) {}
+
+// Enabled reports whether entries of the V-level are logged.
+func (s *Sink) Enabled(level int) bool {
+	if level <= 0 {
+		return s.l.Enabled(onelog.INFO)
+	}
+	return s.l.Enabled(onelog.DEBUG) && (s.verbosity < 0 || level <= s.verbosity)
+}
+
+// Info logs a non error entry of the V-level with the key/value pairs.
+func (s *Sink) Info(level int, msg string, keysAndValues ...interface{}) {
+	if level <= 0 {
+		s.l.InfoWithFields(msg, s.fields(keysAndValues, func(onelog.Entry) {}))
+		return
+	}
+	if s.verbosity >= 0 && level > s.verbosity {
+		return
+	}
+	s.l.DebugWithFields(msg, s.fields(keysAndValues, func(e onelog.Entry) {
+		e.Int("v", level)
+	}))
+}
+
+// Error logs an error entry with the key/value pairs.
+func (s *Sink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.l.ErrorWithFields(msg, s.fields(keysAndValues, func(e onelog.Entry) {
+		e.Err("error", err)
+	}))
+}
+
+// WithValues returns a sink adding the key/value pairs to each entry.
+func (s *Sink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	if len(keysAndValues) == 0 {
+		return s
+	}
+	return &Sink{
+		l: s.l.WithStatic(func(e onelog.Entry) {
+			addKeyValues(e, keysAndValues)
+		}),
+		name:      s.name,
+		verbosity: s.verbosity,
+	}
+}
+
+// WithName returns a sink appending name to the "logger" field, joined with a dot.
+func (s *Sink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "." + name
+	}
+	return &Sink{l: s.l, name: name, verbosity: s.verbosity}
+}
+
+// fields returns the fields of an entry: the name, the fields added by f and the key/value pairs.
+func (s *Sink) fields(keysAndValues []interface{}, f func(onelog.Entry)) func(onelog.Entry) {
+	return func(e onelog.Entry) {
+		if s.name != "" {
+			e.String("logger", s.name)
+		}
+		f(e)
+		addKeyValues(e, keysAndValues)
+	}
+}
+
+// addKeyValues adds the key/value pairs to the entry.
+// Keys which are not strings are formatted with fmt and a key without value is added as null.
+// Values implementing logr.Marshaler are replaced with the result of MarshalLog.
+func addKeyValues(e onelog.Entry, keysAndValues []interface{}) {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		k, ok := keysAndValues[i].(string)
+		if !ok {
+			k = strings.TrimSpace(fmt.Sprint(keysAndValues[i]))
+		}
+		if i+1 == len(keysAndValues) {
+			e.Any(k, nil)
+			return
+		}
+		v := keysAndValues[i+1]
+		if m, ok := v.(logr.Marshaler); ok {
+			v = m.MarshalLog()
+		}
+		e.Any(k, v)
+	}
+}
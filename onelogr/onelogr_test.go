@@ -0,0 +1,70 @@
+package onelogr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/francoispqt/onelog"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct{ id int }
+
+func (u user) MarshalLog() interface{} {
+	return map[string]int{"id": u.id}
+}
+
+func TestSink(t *testing.T) {
+	t.Run("info", func(t *testing.T) {
+		w := &strings.Builder{}
+		New(onelog.New(w, onelog.ALL)).Info("reconciling", "name", "web", "replicas", 3)
+		assert.Equal(t, `{"level":"info","message":"reconciling","name":"web","replicas":3}`+"\n", w.String(), "entry should be written with INFO")
+	})
+	t.Run("v-levels", func(t *testing.T) {
+		w := &strings.Builder{}
+		l := New(onelog.New(w, onelog.ALL))
+		l.V(1).Info("detail")
+		l.V(4).Info("trace")
+		assert.Equal(t, `{"level":"debug","message":"detail","v":1}`+"\n"+`{"level":"debug","message":"trace","v":4}`+"\n", w.String(), "entries should be written with DEBUG")
+	})
+	t.Run("verbosity", func(t *testing.T) {
+		w := &strings.Builder{}
+		l := logr.New(NewSink(onelog.New(w, onelog.ALL)).Verbosity(1))
+		assert.True(t, l.V(1).Enabled(), "V(1) should be enabled")
+		assert.False(t, l.V(2).Enabled(), "V(2) should not be enabled")
+		l.V(2).Info("trace")
+		assert.Equal(t, "", w.String(), "entry should not be written")
+	})
+	t.Run("enabled", func(t *testing.T) {
+		l := New(onelog.New(nil, onelog.INFO|onelog.ERROR))
+		assert.True(t, l.Enabled(), "V(0) should be enabled")
+		assert.False(t, l.V(1).Enabled(), "V(1) should not be enabled without DEBUG")
+	})
+	t.Run("error", func(t *testing.T) {
+		w := &strings.Builder{}
+		New(onelog.New(w, onelog.ALL)).Error(errors.New("conflict"), "update failed", "attempt", 2)
+		assert.Equal(t, `{"level":"error","message":"update failed","error":"conflict","attempt":2}`+"\n", w.String(), "entry should be written with ERROR")
+	})
+	t.Run("error-nil", func(t *testing.T) {
+		w := &strings.Builder{}
+		New(onelog.New(w, onelog.ALL)).Error(nil, "update failed")
+		assert.Equal(t, `{"level":"error","message":"update failed"}`+"\n", w.String(), "entry should be written without error")
+	})
+	t.Run("with-values-and-name", func(t *testing.T) {
+		w := &strings.Builder{}
+		l := New(onelog.New(w, onelog.ALL)).
+			WithName("controller").
+			WithValues("kind", "Deployment").
+			WithName("deployment").
+			WithValues("namespace", "prod")
+		l.Info("synced", "user", user{id: 42})
+		assert.Equal(t, `{"level":"info","message":"synced","kind":"Deployment","namespace":"prod","logger":"controller.deployment","user":{"id":42}}`+"\n", w.String(), "entry should have the values and name")
+	})
+	t.Run("odd-and-non-string-keys", func(t *testing.T) {
+		w := &strings.Builder{}
+		New(onelog.New(w, onelog.ALL)).Info("odd", 1, "one", "missing")
+		assert.Equal(t, `{"level":"info","message":"odd","1":"one","missing":null}`+"\n", w.String(), "keys should be formatted and missing values null")
+	})
+}
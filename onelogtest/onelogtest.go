@@ -0,0 +1,321 @@
+// Package onelogtest provides helpers to test code logging with onelog.
+//
+// An observer logger records each entry written as a decoded Entry, which can be queried
+// and asserted on without comparing raw JSON:
+//
+//	logger, logs := onelogtest.New(onelog.ALL)
+//	doWork(logger)
+//	logs.FilterLevel(onelog.ERROR).AssertLen(t, 0)
+//	logs.AssertLogged(t, onelog.INFO, "done").AssertField(t, "items", 3)
+package onelogtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/francoispqt/onelog"
+)
+
+// Entry is a decoded log entry.
+type Entry struct {
+	Level   uint8
+	Message string
+	// Fields holds the fields outside of the context namespace, decoded with encoding/json.
+	Fields map[string]interface{}
+	// Keys holds the keys of Fields in the order they were written.
+	Keys []string
+	// ContextName is the name of the context namespace, set with onelog.NewContext or WithContext, if any.
+	ContextName string
+	// Context holds the fields of the context namespace.
+	Context map[string]interface{}
+	// Raw is the line written by the logger, without its trailing newline.
+	// Lines which are not valid JSON objects are recorded with only Raw set.
+	Raw string
+}
+
+// Field returns the value of the field k, looked up in Fields then in Context.
+func (e Entry) Field(k string) (interface{}, bool) {
+	if v, ok := e.Fields[k]; ok {
+		return v, true
+	}
+	v, ok := e.Context[k]
+	return v, ok
+}
+
+// AssertField reports an error on t if the entry has no field k equal to v.
+// v is compared with the field once encoded and decoded with encoding/json, so 42 equals the decoded float64 42.
+func (e Entry) AssertField(t testing.TB, k string, v interface{}) {
+	t.Helper()
+	got, ok := e.Field(k)
+	if !ok {
+		t.Errorf("onelogtest: entry %q has no field %q: %s", e.Message, k, e.Raw)
+		return
+	}
+	if !equal(got, v) {
+		t.Errorf("onelogtest: field %q of entry %q is %v, expected %v", k, e.Message, got, v)
+	}
+}
+
+// Logs holds the entries recorded by an observer logger. It is safe for concurrent use.
+type Logs struct {
+	mu           sync.Mutex
+	entries      []Entry
+	contextNames []string
+	partial      []byte
+}
+
+// New returns a logger with the given levels recording its entries in the returned Logs.
+func New(levels uint8) (*onelog.Logger, *Logs) {
+	logs := &Logs{}
+	return onelog.New(logs, levels), logs
+}
+
+// NewContext returns a logger with the given levels and context name recording its entries in the returned Logs.
+func NewContext(levels uint8, contextName string) (*onelog.Logger, *Logs) {
+	logs := &Logs{contextNames: []string{contextName}}
+	return onelog.NewContext(logs, levels, contextName), logs
+}
+
+// ContextNames adds names of context namespaces to separate from the fields,
+// for loggers derived with WithContext. It must be called before entries are written.
+func (o *Logs) ContextNames(names ...string) *Logs {
+	o.mu.Lock()
+	o.contextNames = append(o.contextNames, names...)
+	o.mu.Unlock()
+	return o
+}
+
+// Write decodes and records each line of p, it is called by the logger.
+func (o *Logs) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := len(p)
+	if len(o.partial) > 0 {
+		p = append(o.partial, p...)
+		o.partial = nil
+	}
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			o.partial = append([]byte(nil), p...)
+			break
+		}
+		if line := p[:i]; len(bytes.TrimSpace(line)) > 0 {
+			o.entries = append(o.entries, o.decode(line))
+		}
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// decode decodes a line, the first key being the level and the second the message
+// whatever the keys set with onelog.LevelKey and onelog.MsgKey.
+func (o *Logs) decode(line []byte) Entry {
+	e := Entry{Raw: string(line)}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return e
+	}
+	fields := map[string]interface{}{}
+	var keys []string
+	for i := 0; dec.More(); i++ {
+		t, err := dec.Token()
+		if err != nil {
+			return Entry{Raw: e.Raw}
+		}
+		k := t.(string)
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return Entry{Raw: e.Raw}
+		}
+		switch {
+		case i == 0:
+			s, _ := v.(string)
+			e.Level = level(s)
+		case i == 1:
+			e.Message, _ = v.(string)
+		case e.ContextName == "" && o.isContextName(k):
+			if m, ok := v.(map[string]interface{}); ok {
+				e.ContextName, e.Context = k, m
+				continue
+			}
+			fallthrough
+		default:
+			if _, ok := fields[k]; !ok {
+				keys = append(keys, k)
+			}
+			fields[k] = v
+		}
+	}
+	e.Fields, e.Keys = fields, keys
+	return e
+}
+
+func (o *Logs) isContextName(k string) bool {
+	for _, n := range o.contextNames {
+		if n == k {
+			return true
+		}
+	}
+	return false
+}
+
+// level returns the level whose text, set with onelog.LevelText, is s.
+func level(s string) uint8 {
+	for _, l := range []uint8{onelog.INFO, onelog.DEBUG, onelog.WARN, onelog.ERROR, onelog.FATAL} {
+		if onelog.Levels[l] == s {
+			return l
+		}
+	}
+	return 0
+}
+
+// Len returns the number of entries recorded.
+func (o *Logs) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.entries)
+}
+
+// All returns a copy of the entries recorded.
+func (o *Logs) All() []Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Entry(nil), o.entries...)
+}
+
+// TakeAll returns the entries recorded and removes them.
+func (o *Logs) TakeAll() []Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entries := o.entries
+	o.entries = nil
+	return entries
+}
+
+// Filter returns the entries for which f returns true.
+func (o *Logs) Filter(f func(Entry) bool) *Logs {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	filtered := &Logs{contextNames: o.contextNames}
+	for _, e := range o.entries {
+		if f(e) {
+			filtered.entries = append(filtered.entries, e)
+		}
+	}
+	return filtered
+}
+
+// FilterLevel returns the entries with one of the levels in the mask.
+func (o *Logs) FilterLevel(levels uint8) *Logs {
+	return o.Filter(func(e Entry) bool {
+		return e.Level&levels != 0
+	})
+}
+
+// FilterMessage returns the entries with the message msg.
+func (o *Logs) FilterMessage(msg string) *Logs {
+	return o.Filter(func(e Entry) bool {
+		return e.Message == msg
+	})
+}
+
+// FilterMessageContains returns the entries whose message contains s.
+func (o *Logs) FilterMessageContains(s string) *Logs {
+	return o.Filter(func(e Entry) bool {
+		return strings.Contains(e.Message, s)
+	})
+}
+
+// FilterField returns the entries with a field k equal to v, compared like in Entry.AssertField.
+func (o *Logs) FilterField(k string, v interface{}) *Logs {
+	return o.Filter(func(e Entry) bool {
+		got, ok := e.Field(k)
+		return ok && equal(got, v)
+	})
+}
+
+// AssertLen reports an error on t if the number of entries is not n.
+func (o *Logs) AssertLen(t testing.TB, n int) {
+	t.Helper()
+	if l := o.Len(); l != n {
+		t.Errorf("onelogtest: %d entries recorded, expected %d%s", l, n, o.dump())
+	}
+}
+
+// AssertLogged reports an error on t if no entry has the level and message, and returns the first one found.
+func (o *Logs) AssertLogged(t testing.TB, level uint8, msg string) Entry {
+	t.Helper()
+	entries := o.FilterLevel(level).FilterMessage(msg).All()
+	if len(entries) == 0 {
+		t.Errorf("onelogtest: no %s entry with message %q recorded%s", onelog.Levels[level], msg, o.dump())
+		return Entry{}
+	}
+	return entries[0]
+}
+
+// AssertNotLogged reports an error on t if an entry has the level and message.
+func (o *Logs) AssertNotLogged(t testing.TB, level uint8, msg string) {
+	t.Helper()
+	if o.FilterLevel(level).FilterMessage(msg).Len() > 0 {
+		t.Errorf("onelogtest: unexpected %s entry with message %q recorded%s", onelog.Levels[level], msg, o.dump())
+	}
+}
+
+// dump returns the raw entries recorded for error messages.
+func (o *Logs) dump() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var b strings.Builder
+	for _, e := range o.entries {
+		b.WriteString("\n\t")
+		b.WriteString(e.Raw)
+	}
+	return b.String()
+}
+
+// equal reports whether the decoded value got equals v once encoded and decoded with encoding/json.
+func equal(got, v interface{}) bool {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	var want interface{}
+	if err := json.Unmarshal(b, &want); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+// Writer is an io.Writer logging each line written with t.Log, so entries are shown with the test's output.
+type Writer struct {
+	t testing.TB
+}
+
+var _ io.Writer = Writer{}
+
+// NewWriter returns a Writer logging lines with t.Log.
+func NewWriter(t testing.TB) Writer {
+	return Writer{t: t}
+}
+
+// Write logs each non empty line of p with t.Log.
+func (w Writer) Write(p []byte) (int, error) {
+	w.t.Helper()
+	for _, line := range strings.Split(string(p), "\n") {
+		if line != "" {
+			w.t.Log(line)
+		}
+	}
+	return len(p), nil
+}
+
+// NewLogger returns a logger with the given levels writing entries with t.Log.
+func NewLogger(t testing.TB, levels uint8) *onelog.Logger {
+	return onelog.New(NewWriter(t), levels)
+}
@@ -0,0 +1,146 @@
+package onelogtest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/francoispqt/onelog"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTB records the messages of Log and Errorf.
+type fakeTB struct {
+	testing.TB
+	logs   []string
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Log(args ...interface{}) {
+	f.logs = append(f.logs, fmt.Sprint(args...))
+}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestObserver(t *testing.T) {
+	t.Run("decode", func(t *testing.T) {
+		logger, logs := New(onelog.ALL)
+		logger.With(func(e onelog.Entry) { e.String("service", "api") }).
+			InfoWith("started").Int("port", 8080).Err("error", errors.New("none")).Write()
+		entries := logs.All()
+		assert.Len(t, entries, 1, "one entry should be recorded")
+		e := entries[0]
+		assert.Equal(t, onelog.INFO, e.Level, "level should be decoded")
+		assert.Equal(t, "started", e.Message, "message should be decoded")
+		assert.Equal(t, []string{"service", "port", "error"}, e.Keys, "keys should be ordered")
+		assert.Equal(t, map[string]interface{}{"service": "api", "port": float64(8080), "error": "none"}, e.Fields, "fields should be decoded")
+		assert.Equal(t, `{"level":"info","message":"started","service":"api","port":8080,"error":"none"}`, e.Raw, "raw line should be kept")
+	})
+	t.Run("context", func(t *testing.T) {
+		logger, logs := NewContext(onelog.ALL, "params")
+		logger.Hook(func(e onelog.Entry) { e.String("host", "a") }).
+			With(func(e onelog.Entry) { e.String("user", "bob") }).
+			WarnWith("denied").Int("code", 403).Write()
+		e := logs.AssertLogged(t, onelog.WARN, "denied")
+		assert.Equal(t, "params", e.ContextName, "context name should be set")
+		assert.Equal(t, map[string]interface{}{"code": float64(403), "user": "bob"}, e.Context, "context fields should be separated")
+		assert.Equal(t, map[string]interface{}{"host": "a"}, e.Fields, "fields should not hold the context")
+		e.AssertField(t, "code", 403)
+	})
+	t.Run("context-names", func(t *testing.T) {
+		logger, logs := New(onelog.ALL)
+		logs.ContextNames("params")
+		logger.WithContext("params").InfoWith("m").String("k", "v").Write()
+		e := logs.AssertLogged(t, onelog.INFO, "m")
+		assert.Equal(t, map[string]interface{}{"k": "v"}, e.Context, "context fields should be separated")
+	})
+	t.Run("custom-keys", func(t *testing.T) {
+		onelog.LevelText(onelog.ERROR, "ERR")
+		onelog.MsgKey("msg")
+		defer func() {
+			onelog.LevelText(onelog.ERROR, "error")
+			onelog.MsgKey("message")
+		}()
+		logger, logs := New(onelog.ALL)
+		logger.Error("boom")
+		logs.AssertLogged(t, onelog.ERROR, "boom")
+	})
+	t.Run("malformed", func(t *testing.T) {
+		_, logs := New(onelog.ALL)
+		logs.Write([]byte("not json\n"))
+		assert.Equal(t, []Entry{{Raw: "not json"}}, logs.All(), "malformed line should be recorded raw")
+	})
+	t.Run("partial-writes", func(t *testing.T) {
+		_, logs := New(onelog.ALL)
+		logs.Write([]byte(`{"level":"info",`))
+		assert.Equal(t, 0, logs.Len(), "partial line should not be recorded")
+		logs.Write([]byte(`"message":"m"}` + "\n"))
+		logs.AssertLogged(t, onelog.INFO, "m")
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		logger, logs := New(onelog.ALL)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				logger.Info("m")
+			}()
+		}
+		wg.Wait()
+		logs.AssertLen(t, 10)
+	})
+}
+
+func TestObserverQueries(t *testing.T) {
+	logger, logs := New(onelog.ALL)
+	logger.InfoWith("request").Int("status", 200).String("path", "/a").Write()
+	logger.WarnWith("request").Int("status", 404).String("path", "/b").Write()
+	logger.ErrorWith("request failed").Int("status", 500).Write()
+	logger.Debug("cache miss")
+
+	assert.Equal(t, 2, logs.FilterLevel(onelog.WARN|onelog.ERROR).Len(), "two entries should be warnings or errors")
+	assert.Equal(t, 2, logs.FilterMessage("request").Len(), "two entries should have the message")
+	assert.Equal(t, 3, logs.FilterMessageContains("request").Len(), "three entries should contain the message")
+	assert.Equal(t, "/b", logs.FilterField("status", 404).All()[0].Fields["path"], "entry should be found by field")
+	assert.Equal(t, 0, logs.FilterField("status", "404").Len(), "field types should be compared")
+	assert.Equal(t, 4, len(logs.TakeAll()), "all entries should be taken")
+	assert.Equal(t, 0, logs.Len(), "no entry should be left")
+}
+
+func TestObserverAssertions(t *testing.T) {
+	logger, logs := New(onelog.ALL)
+	logger.InfoWith("done").Int("items", 3).Write()
+
+	tb := &fakeTB{}
+	logs.AssertLen(tb, 1)
+	logs.AssertLogged(tb, onelog.INFO, "done").AssertField(tb, "items", 3)
+	logs.AssertNotLogged(tb, onelog.ERROR, "done")
+	assert.Empty(t, tb.errors, "assertions should pass")
+
+	logs.AssertLen(tb, 2)
+	logs.AssertLogged(tb, onelog.ERROR, "done")
+	logs.AssertNotLogged(tb, onelog.INFO, "done")
+	logs.AssertLogged(tb, onelog.INFO, "done").AssertField(tb, "items", 4)
+	logs.AssertLogged(tb, onelog.INFO, "done").AssertField(tb, "missing", 4)
+	assert.Equal(t, []string{
+		"onelogtest: 1 entries recorded, expected 2\n\t" + `{"level":"info","message":"done","items":3}`,
+		"onelogtest: no error entry with message \"done\" recorded\n\t" + `{"level":"info","message":"done","items":3}`,
+		"onelogtest: unexpected info entry with message \"done\" recorded\n\t" + `{"level":"info","message":"done","items":3}`,
+		`onelogtest: field "items" of entry "done" is 3, expected 4`,
+		`onelogtest: entry "done" has no field "missing": {"level":"info","message":"done","items":3}`,
+	}, tb.errors, "assertions should fail")
+}
+
+func TestWriter(t *testing.T) {
+	tb := &fakeTB{}
+	logger := NewLogger(tb, onelog.ALL)
+	logger.Info("one")
+	NewWriter(tb).Write([]byte("a\n\nb\n"))
+	assert.Equal(t, []string{`{"level":"info","message":"one"}`, "a", "b"}, tb.logs, "lines should be logged with t.Log")
+}
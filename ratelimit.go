@@ -0,0 +1,163 @@
+package onelog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimiter puts hard caps on the number of entries written per second.
+//
+// Limits are token buckets attached to a level mask, all levels in the mask share the same bucket.
+// When a bucket is empty, entries are suppressed before an encoder is borrowed.
+// FATAL entries are never suppressed, so the logger always exits after writing them.
+// A RateLimiter is shared by loggers derived with With and WithContext,
+// it is safe for concurrent use.
+type RateLimiter struct {
+	buckets    [sampleLevels]*tokenBucket
+	suppressed [sampleLevels]uint64
+	pending    [sampleLevels]uint64
+	interval   int64
+	lastReport int64
+	summary    bool
+	onSuppress func(level uint8, suppressed uint64)
+	timer      reportTimer
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   int64
+}
+
+// NewRateLimiter returns a RateLimiter without any limit.
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		interval:   int64(time.Second),
+		lastReport: now().UnixNano(),
+	}
+}
+
+// Limit adds a token bucket shared by all levels in the mask.
+// Entries are let through at most perSecond times per second on average,
+// with bursts of up to burst entries.
+func (r *RateLimiter) Limit(levels uint8, perSecond float64, burst int) *RateLimiter {
+	b := &tokenBucket{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   now().UnixNano(),
+	}
+	for i := range r.buckets {
+		if levels&(1<<uint(i)) != 0 {
+			r.buckets[i] = b
+		}
+	}
+	return r
+}
+
+// Summary enables a summary entry with message "rate limited" and a "suppressed" field
+// written at most once per interval for each level having suppressed entries.
+// The summary is written with the next entry let through after the interval elapsed
+// or, if there is none, by a timer when it elapses.
+func (r *RateLimiter) Summary(interval time.Duration) *RateLimiter {
+	r.summary = true
+	r.interval = int64(interval)
+	return r
+}
+
+// OnSuppress sets a callback reporting the number of entries suppressed per level,
+// it is called at most once per summary interval for each level having suppressed entries,
+// like the summary entry, from a timer goroutine when no entry is let through.
+func (r *RateLimiter) OnSuppress(f func(level uint8, suppressed uint64)) *RateLimiter {
+	r.onSuppress = f
+	return r
+}
+
+// Suppressed returns the total number of entries suppressed at the given level.
+func (r *RateLimiter) Suppressed(level uint8) uint64 {
+	return atomic.LoadUint64(&r.suppressed[levelIndex(level)])
+}
+
+// RateLimiter sets the rate limiter used by the logger and returns it.
+// The rate limiter is shared by loggers derived with With and WithContext.
+func (l *Logger) RateLimiter(r *RateLimiter) *Logger {
+	l.limiter = r
+	return l
+}
+
+func (r *RateLimiter) allow(l *Logger, level uint8) bool {
+	lvl := levelIndex(level)
+	b := r.buckets[lvl]
+	if b == nil {
+		return true
+	}
+	t := now().UnixNano()
+	if !b.take(t) {
+		atomic.AddUint64(&r.suppressed[lvl], 1)
+		atomic.AddUint64(&r.pending[lvl], 1)
+		if r.summary || r.onSuppress != nil {
+			r.reportAt(l, atomic.LoadInt64(&r.lastReport)+r.interval, t)
+		}
+		return false
+	}
+	if r.summary || r.onSuppress != nil {
+		r.report(l, t)
+	}
+	return true
+}
+
+// report writes the summary entries and calls the callback
+// if the interval elapsed since the last report.
+func (r *RateLimiter) report(l *Logger, t int64) {
+	last := atomic.LoadInt64(&r.lastReport)
+	if t-last < r.interval || !atomic.CompareAndSwapInt64(&r.lastReport, last, t) {
+		return
+	}
+	for i := range r.pending {
+		n := atomic.SwapUint64(&r.pending[i], 0)
+		if n == 0 {
+			continue
+		}
+		level := uint8(1) << uint(i)
+		if r.onSuppress != nil {
+			r.onSuppress(level, n)
+		}
+		if r.summary {
+			l.writeInternal(level, "rate limited", func(e Entry) {
+				e.enc.Uint64Key("suppressed", n)
+			})
+		}
+	}
+}
+
+// reportAt arms the timer reporting suppressed entries at the time due if none is armed, t being the current time.
+func (r *RateLimiter) reportAt(l *Logger, due, t int64) {
+	r.timer.arm(time.Duration(due-t), func() {
+		r.report(l, due)
+		// entries suppressed after a report written in the meantime are reported at the next interval.
+		if next := atomic.LoadInt64(&r.lastReport) + r.interval; hasPending(&r.pending) {
+			r.reportAt(l, next, due)
+		}
+	})
+}
+
+func (b *tokenBucket) take(t int64) bool {
+	b.mu.Lock()
+	if elapsed := t - b.last; elapsed > 0 {
+		b.tokens += float64(elapsed) / float64(time.Second) * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = t
+	}
+	if b.tokens < 1 {
+		b.mu.Unlock()
+		return false
+	}
+	b.tokens--
+	b.mu.Unlock()
+	return true
+}
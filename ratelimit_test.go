@@ -0,0 +1,116 @@
+package onelog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("burst-then-suppress", func(t *testing.T) {
+		defer setNow(time.Now())()
+		w := &bytes.Buffer{}
+		r := NewRateLimiter().Limit(ERROR, 10, 3)
+		logger := New(w, ALL).RateLimiter(r)
+		for i := 0; i < 5; i++ {
+			logger.Error("message")
+			logger.Info("message")
+		}
+		assert.Equal(t, 8, bytes.Count(w.Bytes(), []byte("\n")), "3 errors and 5 infos should have been written")
+		assert.Equal(t, uint64(2), r.Suppressed(ERROR), "2 errors should have been suppressed")
+		assert.Equal(t, uint64(0), r.Suppressed(INFO), "no info should have been suppressed")
+	})
+	t.Run("refill", func(t *testing.T) {
+		start := time.Now()
+		defer setNow(start)()
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).RateLimiter(NewRateLimiter().Limit(WARN, 10, 1))
+		logger.Warn("message")
+		logger.Warn("message")
+		now = func() time.Time { return start.Add(100 * time.Millisecond) }
+		logger.Warn("message")
+		logger.Warn("message")
+		assert.Equal(t, 2, bytes.Count(w.Bytes(), []byte("\n")), "2 entries should have been written")
+	})
+	t.Run("shared-level-mask", func(t *testing.T) {
+		defer setNow(time.Now())()
+		w := &bytes.Buffer{}
+		r := NewRateLimiter().Limit(ERROR|WARN, 1, 2)
+		logger := New(w, ALL).RateLimiter(r)
+		logger.ErrorWith("message").Write()
+		logger.WarnWithFields("message", func(e Entry) {})
+		logger.With(func(e Entry) {}).ErrorWithFields("message", func(e Entry) {})
+		logger.WithContext("ctx").Warn("message")
+		assert.Equal(t, 2, bytes.Count(w.Bytes(), []byte("\n")), "2 entries should have been written")
+		assert.Equal(t, uint64(1), r.Suppressed(ERROR), "1 error should have been suppressed")
+		assert.Equal(t, uint64(1), r.Suppressed(WARN), "1 warning should have been suppressed")
+	})
+	t.Run("fatal-never-suppressed", func(t *testing.T) {
+		defer setNow(time.Now())()
+		w := &bytes.Buffer{}
+		r := NewRateLimiter().Limit(FATAL, 1, 1)
+		logger := New(w, ALL).RateLimiter(r)
+		exited := 0
+		logger.ExitFn = func(int) { exited++ }
+		logger.Fatal("message")
+		logger.Fatal("message")
+		logger.FatalWith("message").Write()
+		logger.FatalWithFields("message", func(e Entry) {})
+		logger.WithContext("ctx").Fatal("message")
+		assert.Equal(t, 5, exited, "logger should exit after each fatal entry")
+		assert.Equal(t, 5, bytes.Count(w.Bytes(), []byte("\n")), "5 entries should have been written")
+		assert.Equal(t, uint64(0), r.Suppressed(FATAL), "no fatal should have been suppressed")
+	})
+	t.Run("summary-and-callback", func(t *testing.T) {
+		start := time.Now()
+		defer setNow(start)()
+		w := &bytes.Buffer{}
+		var reported uint64
+		logger := New(w, ALL).RateLimiter(
+			NewRateLimiter().
+				Limit(ERROR, 1, 1).
+				Summary(time.Second).
+				OnSuppress(func(level uint8, n uint64) {
+					assert.Equal(t, ERROR, level, "level should be error")
+					reported += n
+				}),
+		)
+		logger.Error("message")
+		logger.Error("message")
+		logger.Error("message")
+		w.Reset()
+		now = func() time.Time { return start.Add(2 * time.Second) }
+		logger.Error("message")
+		assert.Equal(
+			t,
+			`{"level":"error","message":"rate limited","suppressed":2}`+"\n"+
+				`{"level":"error","message":"message"}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+		assert.Equal(t, uint64(2), reported, "callback should have reported 2 suppressed entries")
+	})
+	t.Run("summary-without-later-entry", func(t *testing.T) {
+		w := make(chanWriter, 2)
+		reported := make(chan uint64, 1)
+		logger := New(w, ALL).RateLimiter(
+			NewRateLimiter().
+				Limit(ERROR, 1, 1).
+				Summary(10 * time.Millisecond).
+				OnSuppress(func(level uint8, n uint64) { reported <- n }),
+		)
+		logger.Error("message")
+		logger.Error("message")
+		logger.Error("message")
+		assert.Equal(t, `{"level":"error","message":"message"}`+"\n", <-w, "first entry should have been written")
+		select {
+		case summary := <-w:
+			assert.Equal(t, `{"level":"error","message":"rate limited","suppressed":2}`+"\n", summary, "summary should be written by the timer")
+		case <-time.After(time.Second):
+			t.Fatal("summary should have been written when the interval elapsed")
+		}
+		assert.Equal(t, uint64(2), <-reported, "callback should have been called by the timer")
+	})
+}
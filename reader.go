@@ -0,0 +1,213 @@
+package onelog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/francoispqt/gojay"
+)
+
+// Record is an entry read from a log stream by a Reader.
+type Record struct {
+	// Line is the line number of the entry in the stream, starting at 1.
+	Line  int
+	Level uint8
+	// LevelText is the level as written, Level is 0 if it is not a text of Levels.
+	LevelText string
+	Message   string
+	// ContextName is the key of the context namespace, see NewContext, if the entry has one.
+	ContextName string
+	// Context holds the fields of the context namespace in order.
+	Context []Field
+	// Fields holds the other fields in order.
+	Fields []Field
+}
+
+// Field is a field of a Record, its value being kept as raw JSON.
+type Field struct {
+	Key   string
+	Value gojay.EmbeddedJSON
+}
+
+// Decode decodes the value of the field into v, a pointer to one of the types supported by gojay.Unmarshal.
+func (f Field) Decode(v interface{}) error {
+	return gojay.Unmarshal(f.Value, v)
+}
+
+// Field returns the field k of the record, looked up in Fields then in Context.
+func (r Record) Field(k string) (Field, bool) {
+	for _, f := range r.Fields {
+		if f.Key == k {
+			return f, true
+		}
+	}
+	for _, f := range r.Context {
+		if f.Key == k {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// ReadError is returned by Reader.Read for a line which is not a valid entry.
+// Reading can go on after it.
+type ReadError struct {
+	Line int
+	Err  error
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("onelog: line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the decoding error.
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads the entries of a newline delimited onelog stream.
+// Levels are mapped back with Levels, and the level and message keys are the ones set
+// with LevelKey and MsgKey when the reader is created unless changed with Keys.
+type Reader struct {
+	r            *bufio.Reader
+	line         int
+	levelKey     string
+	msgKey       string
+	contextNames []string
+}
+
+// NewReader returns a Reader reading entries from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r:        bufio.NewReader(r),
+		levelKey: levelKey,
+		msgKey:   msgKey,
+	}
+}
+
+// Keys sets the keys of the level and message fields.
+func (r *Reader) Keys(levelKey, msgKey string) *Reader {
+	r.levelKey = levelKey
+	r.msgKey = msgKey
+	return r
+}
+
+// ContextNames sets the names of the context namespaces separated from the fields of records.
+func (r *Reader) ContextNames(names ...string) *Reader {
+	r.contextNames = append(r.contextNames, names...)
+	return r
+}
+
+// Read returns the next entry of the stream, skipping empty lines.
+// It returns io.EOF at the end of the stream and a *ReadError for a line which is not a valid entry.
+func (r *Reader) Read() (Record, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return Record{}, err
+		}
+		r.line++
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		rec, err := r.Decode(line)
+		rec.Line = r.line
+		if err != nil {
+			return rec, &ReadError{Line: r.line, Err: err}
+		}
+		return rec, nil
+	}
+}
+
+// Decode decodes a single entry with the reader's keys and context names, without reading the stream.
+func (r *Reader) Decode(line []byte) (Record, error) {
+	var rec Record
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return rec, fmt.Errorf("entry is not a JSON object")
+	}
+	// gojay accepts truncated objects, they are rejected before decoding.
+	if !eachMember(line, func([]byte, int, int) bool { return true }) {
+		return rec, fmt.Errorf("entry is not a well formed JSON object")
+	}
+	if err := gojay.UnmarshalJSONObject(line, &recordDecoder{r: r, rec: &rec}); err != nil {
+		return rec, err
+	}
+	return rec, nil
+}
+
+type recordDecoder struct {
+	r   *Reader
+	rec *Record
+}
+
+func (d *recordDecoder) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
+	switch {
+	case k == d.r.levelKey:
+		if err := dec.String(&d.rec.LevelText); err != nil {
+			return err
+		}
+		d.rec.Level = levelOf(d.rec.LevelText)
+		return nil
+	case k == d.r.msgKey:
+		return dec.String(&d.rec.Message)
+	}
+	var v gojay.EmbeddedJSON
+	if err := dec.EmbeddedJSON(&v); err != nil {
+		return err
+	}
+	if d.rec.ContextName == "" && len(v) > 0 && v[0] == '{' && d.r.isContextName(k) {
+		fields := fieldsDecoder{}
+		if err := gojay.UnmarshalJSONObject(v, &fields); err != nil {
+			return err
+		}
+		d.rec.ContextName, d.rec.Context = k, fields
+		return nil
+	}
+	d.rec.Fields = append(d.rec.Fields, Field{Key: k, Value: v})
+	return nil
+}
+
+func (d *recordDecoder) NKeys() int {
+	return 0
+}
+
+type fieldsDecoder []Field
+
+func (d *fieldsDecoder) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
+	var v gojay.EmbeddedJSON
+	if err := dec.EmbeddedJSON(&v); err != nil {
+		return err
+	}
+	*d = append(*d, Field{Key: k, Value: v})
+	return nil
+}
+
+func (d *fieldsDecoder) NKeys() int {
+	return 0
+}
+
+func (r *Reader) isContextName(k string) bool {
+	for _, n := range r.contextNames {
+		if n == k {
+			return true
+		}
+	}
+	return false
+}
+
+// levelOf returns the level whose text in Levels is s, or 0.
+func levelOf(s string) uint8 {
+	if s == "" {
+		return 0
+	}
+	for i := 1; i < len(Levels); i++ {
+		if Levels[i] == s {
+			return uint8(i)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,108 @@
+package onelog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReader(t *testing.T) {
+	t.Run("entries", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL)
+		logger.InfoWith("started").Int("port", 8080).String("host", "a").Write()
+		logger.ErrorWith("failed").Err("error", errors.New("boom")).Strings("tags", []string{"x"}).Write()
+
+		r := NewReader(w)
+		rec, err := r.Read()
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, Record{
+			Line:      1,
+			Level:     INFO,
+			LevelText: "info",
+			Message:   "started",
+			Fields:    []Field{{"port", []byte("8080")}, {"host", []byte(`"a"`)}},
+		}, rec, "record should be decoded")
+		var port int
+		assert.Nil(t, rec.Fields[0].Decode(&port), "err should be nil")
+		assert.Equal(t, 8080, port, "field should be decoded")
+
+		rec, err = r.Read()
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, ERROR, rec.Level, "level should be mapped back")
+		f, ok := rec.Field("tags")
+		assert.True(t, ok, "field should be found")
+		assert.Equal(t, `["x"]`, string(f.Value), "array should be kept as raw JSON")
+
+		_, err = r.Read()
+		assert.Equal(t, io.EOF, err, "err should be io.EOF")
+	})
+	t.Run("context", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		NewContext(w, ALL, "params").
+			Hook(func(e Entry) { e.String("host", "a") }).
+			WarnWith("denied").Int("code", 403).Write()
+		rec, err := NewReader(w).ContextNames("params").Read()
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, "params", rec.ContextName, "context name should be set")
+		assert.Equal(t, []Field{{"code", []byte("403")}}, rec.Context, "context fields should be separated")
+		assert.Equal(t, []Field{{"host", []byte(`"a"`)}}, rec.Fields, "fields should not hold the context")
+		f, ok := rec.Field("code")
+		assert.True(t, ok, "context field should be found")
+		assert.Equal(t, "403", string(f.Value), "context field should be found")
+	})
+	t.Run("custom-keys", func(t *testing.T) {
+		r := NewReader(strings.NewReader(`{"lvl":"warn","msg":"m","message":"field"}`)).Keys("lvl", "msg")
+		rec, err := r.Read()
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, WARN, rec.Level, "level should be read from the custom key")
+		assert.Equal(t, "m", rec.Message, "message should be read from the custom key")
+		assert.Equal(t, []Field{{"message", []byte(`"field"`)}}, rec.Fields, "message key should be a field")
+	})
+	t.Run("unknown-level", func(t *testing.T) {
+		rec, err := NewReader(strings.NewReader(`{"level":"trace","message":"m"}`)).Read()
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, uint8(0), rec.Level, "level should be 0")
+		assert.Equal(t, "trace", rec.LevelText, "level text should be kept")
+	})
+	t.Run("malformed", func(t *testing.T) {
+		r := NewReader(strings.NewReader("{\"level\":\"info\",\"message\":\"a\"}\n\nnot json\n{\"level\":\"info\",\"message\":\n[1]\n{\"level\":\"info\",\"message\":\"b\"}"))
+		rec, err := r.Read()
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, "a", rec.Message, "first entry should be read")
+
+		_, err = r.Read()
+		var readErr *ReadError
+		assert.True(t, errors.As(err, &readErr), "err should be a *ReadError")
+		assert.Equal(t, 3, readErr.Line, "line number should skip empty lines")
+		assert.Equal(t, "onelog: line 3: entry is not a JSON object", err.Error(), "error message should hold the line")
+
+		_, err = r.Read()
+		assert.True(t, errors.As(err, &readErr), "err should be a *ReadError")
+		assert.Equal(t, 4, readErr.Line, "line number should be set")
+
+		_, err = r.Read()
+		assert.True(t, errors.As(err, &readErr), "err should be a *ReadError")
+		assert.Equal(t, 5, readErr.Line, "line number should be set")
+
+		rec, err = r.Read()
+		assert.Nil(t, err, "err should be nil")
+		assert.Equal(t, Record{Line: 6, Level: INFO, LevelText: "info", Message: "b"}, rec, "last line without newline should be read")
+
+		_, err = r.Read()
+		assert.Equal(t, io.EOF, err, "err should be io.EOF")
+	})
+}
+
+func TestReaderDecode(t *testing.T) {
+	r := NewReader(nil).ContextNames("params")
+	rec, err := r.Decode([]byte(`{"level":"debug","message":"m","params":{"a":1}}`))
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, Record{Level: DEBUG, LevelText: "debug", Message: "m", ContextName: "params", Context: []Field{{"a", []byte("1")}}}, rec, "record should be decoded")
+	_, err = r.Decode([]byte(`{"level":"debug",`))
+	assert.NotNil(t, err, "err should not be nil")
+}
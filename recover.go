@@ -0,0 +1,56 @@
+package onelog
+
+import (
+	"runtime/debug"
+)
+
+// Go runs f in a new goroutine, panics are recovered and logged on l with Recover.
+func Go(l *Logger, f func()) {
+	go func() {
+		defer Recover(l)
+		f()
+	}()
+}
+
+// GoRepanic runs f in a new goroutine, panics are logged on l with RecoverRepanic then re-panicked,
+// crashing the program like an unrecovered panic but with the panic logged.
+func GoRepanic(l *Logger, f func()) {
+	go func() {
+		defer RecoverRepanic(l)
+		f()
+	}()
+}
+
+// Recover recovers a panic and logs it on l with LogPanic, it must be deferred:
+//
+//	defer onelog.Recover(logger)
+func Recover(l *Logger) {
+	if v := recover(); v != nil {
+		LogPanic(l, v, nil)
+	}
+}
+
+// RecoverRepanic recovers a panic, logs it on l with LogPanic and re-panics with the same value,
+// it must be deferred:
+//
+//	defer onelog.RecoverRepanic(logger)
+func RecoverRepanic(l *Logger) {
+	if v := recover(); v != nil {
+		LogPanic(l, v, nil)
+		panic(v)
+	}
+}
+
+// LogPanic logs with ERROR the recovered panic value v, encoded with Entry.Any in a "panic" field,
+// the stack trace of the current goroutine in a "stack" field and the fields added by fields, if not nil.
+// It must be called from the deferred function which recovered the panic for the stack trace to include the panic.
+func LogPanic(l *Logger, v interface{}, fields func(Entry)) {
+	stack := debug.Stack()
+	l.ErrorWithFields("panic", func(e Entry) {
+		e.Any("panic", v)
+		e.String("stack", string(stack))
+		if fields != nil {
+			fields(e)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package onelog
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type chanWriter chan string
+
+func (c chanWriter) Write(p []byte) (int, error) {
+	c <- string(p)
+	return len(p), nil
+}
+
+func TestGo(t *testing.T) {
+	w := make(chanWriter, 1)
+	logger := New(w, ALL).With(func(e Entry) { e.String("worker", "w1") })
+	Go(logger, func() {
+		panic(errors.New("boom"))
+	})
+	out := <-w
+	assert.True(t, strings.HasPrefix(out, `{"level":"error","message":"panic","worker":"w1","panic":"boom","stack":"goroutine `), out)
+	assert.Contains(t, out, "recover_test.go")
+}
+
+func TestRecover(t *testing.T) {
+	w := &bytes.Buffer{}
+	logger := New(w, ALL)
+	func() {
+		defer Recover(logger)
+		panic(map[string]int{"code": 1})
+	}()
+	assert.True(t, strings.HasPrefix(w.String(), `{"level":"error","message":"panic","panic":{"code":1},"stack":"goroutine `), w.String())
+
+	w.Reset()
+	func() {
+		defer Recover(logger)
+	}()
+	assert.Equal(t, ``, w.String(), "nothing should be logged without panic")
+}
+
+func TestRecoverRepanic(t *testing.T) {
+	w := &bytes.Buffer{}
+	logger := New(w, ALL)
+	assert.PanicsWithValue(t, "boom", func() {
+		defer RecoverRepanic(logger)
+		panic("boom")
+	})
+	assert.True(t, strings.HasPrefix(w.String(), `{"level":"error","message":"panic","panic":"boom","stack":"goroutine `), w.String())
+}
+
+func TestLogPanic(t *testing.T) {
+	w := &bytes.Buffer{}
+	logger := New(w, ALL)
+	func() {
+		defer func() {
+			LogPanic(logger, recover(), func(e Entry) { e.String("job", "sync") })
+		}()
+		panic("boom")
+	}()
+	assert.True(t, strings.HasPrefix(w.String(), `{"level":"error","message":"panic","panic":"boom","stack":"goroutine `), w.String())
+	assert.True(t, strings.HasSuffix(w.String(), `,"job":"sync"}`+"\n"), w.String())
+
+	w.Reset()
+	func() {
+		defer Recover(logger)
+		panic((*url.Error)(nil))
+	}()
+	assert.True(t, strings.HasPrefix(w.String(), `{"level":"error","message":"panic","panic":null,"stack":"goroutine `), w.String())
+}
@@ -0,0 +1,310 @@
+package onelog
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Detector reports whether a string value is sensitive and must be redacted.
+type Detector func(v string) bool
+
+// Redactor replaces or masks sensitive values of entry fields before they are encoded.
+//
+// A field is sensitive if its key matches one of the configured keys or patterns,
+// at any depth (nested ObjectFunc objects and dictionaries, WithContext namespaces, With and hook fields),
+// or if it is a string value matched by one of the configured detectors.
+// Values of objects and arrays passed as gojay marshalers
+// or encoded with encoding/json by Any are redacted as a whole when their key matches but are not inspected.
+type Redactor struct {
+	keys        []string
+	detectors   []Detector
+	replacement string
+	keep        int
+	hashKey     []byte
+}
+
+// NewRedactor returns a Redactor replacing sensitive values with "[REDACTED]".
+func NewRedactor() *Redactor {
+	return &Redactor{
+		replacement: "[REDACTED]",
+	}
+}
+
+// Keys adds the keys of sensitive fields, matched case insensitively.
+// A key can be a pattern where * matches any sequence of characters, like "*token*".
+func (r *Redactor) Keys(keys ...string) *Redactor {
+	r.keys = append(r.keys, keys...)
+	return r
+}
+
+// Detect adds detectors of sensitive string values.
+func (r *Redactor) Detect(detectors ...Detector) *Redactor {
+	r.detectors = append(r.detectors, detectors...)
+	return r
+}
+
+// Replace sets the text replacing sensitive values.
+func (r *Redactor) Replace(s string) *Redactor {
+	r.replacement = s
+	return r
+}
+
+// Mask masks sensitive string values with '*' keeping the last keep characters in clear,
+// values shorter than twice keep are entirely masked.
+func (r *Redactor) Mask(keep int) *Redactor {
+	r.keep = keep
+	r.hashKey = nil
+	return r
+}
+
+// Hash replaces sensitive string and number values with a keyed hash ("hash:" followed by 16 hex characters)
+// of their text so values remain correlatable across entries without being readable.
+func (r *Redactor) Hash(key []byte) *Redactor {
+	r.hashKey = key
+	r.keep = 0
+	return r
+}
+
+// Redactor sets the redactor used by the logger and returns it.
+// The redactor is shared by loggers derived with With and WithContext.
+func (l *Logger) Redactor(r *Redactor) *Logger {
+	l.redactor = r
+	return l
+}
+
+// redacts reports whether the field with key k must be redacted by the entry's logger.
+func (e Entry) redacts(k string) bool {
+	return e.l != nil && e.l.redactor != nil && e.l.redactor.matchKey(k)
+}
+
+// redact writes the replacement text for the key k if it must be redacted
+// and reports whether it did so.
+func (e Entry) redact(k string) bool {
+	if !e.redacts(k) {
+		return false
+	}
+	e.enc.StringKey(k, e.l.redactor.replacement)
+	return true
+}
+
+// redactInt is like redact for the integer field k holding v, see redactNumber.
+func (e Entry) redactInt(k string, v int64) bool {
+	if !e.redacts(k) {
+		return false
+	}
+	var b [24]byte
+	e.redactNumber(k, strconv.AppendInt(b[:0], v, 10))
+	return true
+}
+
+// redactUint is like redact for the unsigned integer field k holding v, see redactNumber.
+func (e Entry) redactUint(k string, v uint64) bool {
+	if !e.redacts(k) {
+		return false
+	}
+	var b [24]byte
+	e.redactNumber(k, strconv.AppendUint(b[:0], v, 10))
+	return true
+}
+
+// redactFloat is like redact for the floating number field k holding v of bitSize bits, see redactNumber.
+func (e Entry) redactFloat(k string, v float64, bitSize int) bool {
+	if !e.redacts(k) {
+		return false
+	}
+	var b [32]byte
+	e.redactNumber(k, strconv.AppendFloat(b[:0], v, 'f', -1, bitSize))
+	return true
+}
+
+// redactAny writes the replacement text for the field k holding v passed to Any, see redactNumber.
+func (e Entry) redactAny(k string, v interface{}) {
+	var b [32]byte
+	switch v := v.(type) {
+	case int:
+		e.redactNumber(k, strconv.AppendInt(b[:0], int64(v), 10))
+	case int8:
+		e.redactNumber(k, strconv.AppendInt(b[:0], int64(v), 10))
+	case int16:
+		e.redactNumber(k, strconv.AppendInt(b[:0], int64(v), 10))
+	case int32:
+		e.redactNumber(k, strconv.AppendInt(b[:0], int64(v), 10))
+	case int64:
+		e.redactNumber(k, strconv.AppendInt(b[:0], v, 10))
+	case uint:
+		e.redactNumber(k, strconv.AppendUint(b[:0], uint64(v), 10))
+	case uint8:
+		e.redactNumber(k, strconv.AppendUint(b[:0], uint64(v), 10))
+	case uint16:
+		e.redactNumber(k, strconv.AppendUint(b[:0], uint64(v), 10))
+	case uint32:
+		e.redactNumber(k, strconv.AppendUint(b[:0], uint64(v), 10))
+	case uint64:
+		e.redactNumber(k, strconv.AppendUint(b[:0], v, 10))
+	case float32:
+		e.redactNumber(k, strconv.AppendFloat(b[:0], float64(v), 'f', -1, 32))
+	case float64:
+		e.redactNumber(k, strconv.AppendFloat(b[:0], v, 'f', -1, 64))
+	default:
+		e.enc.StringKey(k, e.l.redactor.replacement)
+	}
+}
+
+// redactNumber writes the replacement text for the number field k whose decimal text is v.
+// In hash mode the text is hashed like string values, so numeric IDs remain correlatable
+// and hash like the same ID passed as a string.
+func (e Entry) redactNumber(k string, v []byte) {
+	r := e.l.redactor
+	if r.hashKey != nil {
+		e.enc.StringKey(k, r.value(string(v)))
+		return
+	}
+	e.enc.StringKey(k, r.replacement)
+}
+
+// stringValue returns the string value v of the field k as it is written to the entry,
+// redacted if needed and cut to the MaxString limit.
+func (e Entry) stringValue(k, v string) string {
+	return e.l.limitString(e.redactString(k, v))
+}
+
+// redactString returns the string value v of the field k, redacted if needed.
+func (e Entry) redactString(k, v string) string {
+	if e.l == nil || e.l.redactor == nil {
+		return v
+	}
+	r := e.l.redactor
+	if r.matchKey(k) {
+		return r.value(v)
+	}
+	for _, d := range r.detectors {
+		if d(v) {
+			return r.value(v)
+		}
+	}
+	return v
+}
+
+func (r *Redactor) matchKey(k string) bool {
+	for _, p := range r.keys {
+		if matchPattern(p, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) value(v string) string {
+	switch {
+	case r.hashKey != nil:
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write([]byte(v))
+		return "hash:" + hex.EncodeToString(mac.Sum(nil)[:8])
+	case r.keep > 0:
+		n := utf8.RuneCountInString(v)
+		if n < r.keep*2 {
+			return strings.Repeat("*", n)
+		}
+		i := len(v)
+		for j := 0; j < r.keep; j++ {
+			_, size := utf8.DecodeLastRuneInString(v[:i])
+			i -= size
+		}
+		return strings.Repeat("*", n-r.keep) + v[i:]
+	default:
+		return r.replacement
+	}
+}
+
+// matchPattern matches s against the pattern p case insensitively, * matching any sequence of bytes.
+func matchPattern(p, s string) bool {
+	star, next := -1, 0
+	i, j := 0, 0
+	for j < len(s) {
+		switch {
+		case i < len(p) && p[i] == '*':
+			star, next = i, j
+			i++
+		case i < len(p) && lower(p[i]) == lower(s[j]):
+			i++
+			j++
+		case star >= 0:
+			next++
+			i, j = star+1, next
+		default:
+			return false
+		}
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
+
+func lower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// CreditCard detects credit card numbers: 13 to 19 digits, optionally separated
+// by spaces or dashes, passing the Luhn checksum.
+func CreditCard(v string) bool {
+	n, sum := 0, 0
+	for i := len(v) - 1; i >= 0; i-- {
+		c := v[i]
+		switch {
+		case c >= '0' && c <= '9':
+			d := int(c - '0')
+			if n%2 == 1 {
+				d *= 2
+				if d > 9 {
+					d -= 9
+				}
+			}
+			sum += d
+			n++
+		case c == ' ' || c == '-':
+		default:
+			return false
+		}
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+// Email detects email addresses.
+func Email(v string) bool {
+	at := strings.IndexByte(v, '@')
+	if at < 1 || at != strings.LastIndexByte(v, '@') || strings.ContainsAny(v, " \t\n") {
+		return false
+	}
+	dot := strings.LastIndexByte(v, '.')
+	return dot > at+1 && dot < len(v)-1
+}
+
+// JWT detects JSON Web Tokens: three base64url segments separated by dots, the header being a JSON object.
+func JWT(v string) bool {
+	if !strings.HasPrefix(v, "eyJ") {
+		return false
+	}
+	dots := 0
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c == '.':
+			if i == 0 || v[i-1] == '.' {
+				return false
+			}
+			dots++
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '=':
+		default:
+			return false
+		}
+	}
+	return dots == 2 && v[len(v)-1] != '.'
+}
@@ -0,0 +1,183 @@
+package onelog
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	sampleBuckets = 1024
+	sampleLevels  = 8
+)
+
+// now returns the current time, it is a variable so tests can control the clock.
+var now = time.Now
+
+// Sampler caps the volume of log entries per level and message.
+//
+// For each (level, message) pair, a Sampler lets the first N entries through
+// during an interval, then every Mth entry, dropping the rest.
+// Pairs are hashed into a fixed set of counters, so sampling never allocates
+// and the decision is taken before an encoder is borrowed, like a disabled level.
+// FATAL entries are never sampled, so the logger always exits after writing them.
+// A Sampler can be shared between loggers, it is safe for concurrent use.
+type Sampler struct {
+	tick       int64
+	first      uint64
+	thereafter uint64
+	summary    bool
+	counters   [sampleLevels][sampleBuckets]sampleCounter
+	dropped    [sampleLevels]uint64
+	pending    [sampleLevels]uint64
+	lastReport int64
+	timer      reportTimer
+}
+
+type sampleCounter struct {
+	resetAt int64
+	count   uint64
+}
+
+// NewSampler returns a Sampler letting through the first entries per level and message
+// during each tick interval and every thereafter-th entry after that.
+// If thereafter is 0, all entries after the first ones are dropped until the next tick.
+func NewSampler(tick time.Duration, first, thereafter uint64) *Sampler {
+	return &Sampler{
+		tick:       int64(tick),
+		first:      first,
+		thereafter: thereafter,
+		lastReport: now().UnixNano(),
+	}
+}
+
+// Summary enables or disables the summary entry reporting the number of dropped entries.
+// When enabled, once per tick interval the logger writes an entry with message "sampled"
+// and a "dropped" field for each level having dropped entries. The summary is written with the next
+// entry let through after the interval elapsed or, if there is none, by a timer when it elapses.
+func (s *Sampler) Summary(enabled bool) *Sampler {
+	s.summary = enabled
+	return s
+}
+
+// Dropped returns the total number of entries dropped at the given level.
+func (s *Sampler) Dropped(level uint8) uint64 {
+	return atomic.LoadUint64(&s.dropped[levelIndex(level)])
+}
+
+// Sampler sets the sampler used by the logger and returns it.
+// The sampler is shared by loggers derived with With and WithContext.
+func (l *Logger) Sampler(s *Sampler) *Logger {
+	l.sampler = s
+	return l
+}
+
+// drop reports whether the entry must be dropped by the sampler or the rate limiter.
+// It is called before borrowing an encoder so dropped entries do not allocate.
+// It is never called for FATAL entries.
+func (l *Logger) drop(level uint8, msg string) bool {
+	if l.sampler != nil && !l.sampler.check(l, level, msg) {
+		return true
+	}
+	if l.limiter != nil && !l.limiter.allow(l, level) {
+		return true
+	}
+	return false
+}
+
+func (s *Sampler) check(l *Logger, level uint8, msg string) bool {
+	t := now().UnixNano()
+	lvl := levelIndex(level)
+	c := &s.counters[lvl][hashMessage(msg)%sampleBuckets]
+	n := c.incr(t, s.tick)
+	if n > s.first && (s.thereafter == 0 || (n-s.first)%s.thereafter != 0) {
+		atomic.AddUint64(&s.dropped[lvl], 1)
+		atomic.AddUint64(&s.pending[lvl], 1)
+		if s.summary {
+			s.reportAt(l, atomic.LoadInt64(&s.lastReport)+s.tick, t)
+		}
+		return false
+	}
+	if s.summary {
+		s.report(l, t)
+	}
+	return true
+}
+
+// report writes the summary entries if the tick interval elapsed since the last one.
+func (s *Sampler) report(l *Logger, t int64) {
+	last := atomic.LoadInt64(&s.lastReport)
+	if t-last < s.tick || !atomic.CompareAndSwapInt64(&s.lastReport, last, t) {
+		return
+	}
+	for i := range s.pending {
+		n := atomic.SwapUint64(&s.pending[i], 0)
+		if n == 0 {
+			continue
+		}
+		l.writeInternal(uint8(1)<<uint(i), "sampled", func(e Entry) {
+			e.enc.Uint64Key("dropped", n)
+		})
+	}
+}
+
+// reportAt arms the timer writing the summary entries at the time due if none is armed, t being the current time.
+func (s *Sampler) reportAt(l *Logger, due, t int64) {
+	s.timer.arm(time.Duration(due-t), func() {
+		s.report(l, due)
+		// entries dropped after a report written in the meantime are reported at the next interval.
+		if next := atomic.LoadInt64(&s.lastReport) + s.tick; hasPending(&s.pending) {
+			s.reportAt(l, next, due)
+		}
+	})
+}
+
+func (c *sampleCounter) incr(t, tick int64) uint64 {
+	resetAt := atomic.LoadInt64(&c.resetAt)
+	if resetAt > t {
+		return atomic.AddUint64(&c.count, 1)
+	}
+	atomic.StoreUint64(&c.count, 1)
+	atomic.StoreInt64(&c.resetAt, t+tick)
+	return 1
+}
+
+func levelIndex(level uint8) int {
+	return bits.TrailingZeros8(level) % sampleLevels
+}
+
+// reportTimer runs a report when no entry lets it run, at most one report is scheduled at once.
+type reportTimer struct {
+	armed int32
+}
+
+// arm runs f after delay unless a run is already scheduled.
+func (rt *reportTimer) arm(delay time.Duration, f func()) {
+	if !atomic.CompareAndSwapInt32(&rt.armed, 0, 1) {
+		return
+	}
+	time.AfterFunc(delay, func() {
+		atomic.StoreInt32(&rt.armed, 0)
+		f()
+	})
+}
+
+// hasPending reports whether a level has entries waiting to be reported.
+func hasPending(pending *[sampleLevels]uint64) bool {
+	for i := range pending {
+		if atomic.LoadUint64(&pending[i]) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hashMessage is an inlined FNV-1a hash, it does not allocate.
+func hashMessage(s string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h
+}
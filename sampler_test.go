@@ -0,0 +1,115 @@
+package onelog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setNow(t time.Time) func() {
+	prev := now
+	now = func() time.Time { return t }
+	return func() { now = prev }
+}
+
+func TestSampler(t *testing.T) {
+	t.Run("first-then-thereafter", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		s := NewSampler(time.Second, 2, 3)
+		logger := New(w, ALL).Sampler(s)
+		for i := 0; i < 8; i++ {
+			logger.Info("message")
+		}
+		// entries 1, 2 then 5 and 8
+		assert.Equal(t, 4, bytes.Count(w.Bytes(), []byte("\n")), "4 entries should have been written")
+		assert.Equal(t, uint64(4), s.Dropped(INFO), "4 entries should have been dropped")
+		assert.Equal(t, uint64(0), s.Dropped(DEBUG), "no debug entry should have been dropped")
+	})
+	t.Run("per-level-and-message", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Sampler(NewSampler(time.Second, 1, 0))
+		logger.InfoWith("foo").Int("i", 1).Write()
+		logger.InfoWith("foo").Int("i", 2).Write()
+		logger.InfoWithFields("bar", func(e Entry) { e.Int("i", 3) })
+		logger.DebugWithFields("foo", func(e Entry) { e.Int("i", 4) })
+		logger.DebugWithFields("foo", func(e Entry) { e.Int("i", 5) })
+		assert.Equal(
+			t,
+			`{"level":"info","message":"foo","i":1}`+"\n"+
+				`{"level":"info","message":"bar","i":3}`+"\n"+
+				`{"level":"debug","message":"foo","i":4}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+	})
+	t.Run("reset-after-tick", func(t *testing.T) {
+		start := time.Now()
+		defer setNow(start)()
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Sampler(NewSampler(time.Second, 1, 0))
+		logger.Warn("message")
+		logger.Warn("message")
+		now = func() time.Time { return start.Add(2 * time.Second) }
+		logger.Warn("message")
+		assert.Equal(t, 2, bytes.Count(w.Bytes(), []byte("\n")), "2 entries should have been written")
+	})
+	t.Run("summary", func(t *testing.T) {
+		start := time.Now()
+		defer setNow(start)()
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Sampler(NewSampler(time.Second, 1, 0).Summary(true))
+		logger.Error("message")
+		logger.Error("message")
+		logger.Error("message")
+		w.Reset()
+		now = func() time.Time { return start.Add(2 * time.Second) }
+		logger.Error("message")
+		assert.Equal(
+			t,
+			`{"level":"error","message":"sampled","dropped":2}`+"\n"+
+				`{"level":"error","message":"message"}`+"\n",
+			w.String(),
+			"bytes written to the writer dont equal expected result",
+		)
+	})
+	t.Run("summary-without-later-entry", func(t *testing.T) {
+		w := make(chanWriter, 2)
+		logger := New(w, ALL).Sampler(NewSampler(10*time.Millisecond, 1, 0).Summary(true))
+		logger.Error("message")
+		logger.Error("message")
+		logger.Error("message")
+		assert.Equal(t, `{"level":"error","message":"message"}`+"\n", <-w, "first entry should have been written")
+		select {
+		case summary := <-w:
+			assert.Equal(t, `{"level":"error","message":"sampled","dropped":2}`+"\n", summary, "summary should be written by the timer")
+		case <-time.After(time.Second):
+			t.Fatal("summary should have been written when the tick interval elapsed")
+		}
+	})
+	t.Run("shared-with-copies", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		s := NewSampler(time.Second, 1, 0)
+		logger := New(w, ALL).Sampler(s)
+		logger.Info("message")
+		logger.With(func(e Entry) { e.String("foo", "bar") }).Info("message")
+		logger.WithContext("ctx").Info("message")
+		assert.Equal(t, uint64(2), s.Dropped(INFO), "2 entries should have been dropped")
+	})
+	t.Run("fatal-never-dropped", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		s := NewSampler(time.Hour, 1, 0)
+		logger := New(w, ALL).Sampler(s)
+		exited := 0
+		logger.ExitFn = func(int) { exited++ }
+		logger.Fatal("message")
+		logger.Fatal("message")
+		logger.FatalWith("message").Write()
+		logger.FatalWithFields("message", func(e Entry) {})
+		logger.WithContext("ctx").FatalWith("message").Write()
+		assert.Equal(t, 5, exited, "logger should exit after each fatal entry")
+		assert.Equal(t, 5, bytes.Count(w.Bytes(), []byte("\n")), "5 entries should have been written")
+		assert.Equal(t, uint64(0), s.Dropped(FATAL), "no fatal should have been dropped")
+	})
+}
@@ -0,0 +1,218 @@
+package onelog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/francoispqt/gojay"
+)
+
+// SlogHandler is a slog.Handler writing records with a Logger, so log/slog and onelog
+// share the same output, hooks, context and write pipeline.
+//
+// Slog levels are mapped to the closest lower onelog level: records below slog.LevelInfo
+// are logged with DEBUG, then INFO, WARN and ERROR. FATAL is never used.
+// The record's time, if non zero, is added as a "time" field formatted with time.RFC3339Nano.
+// Attributes added with WithAttrs are encoded once like WithStatic fields,
+// and groups are encoded as nested objects.
+type SlogHandler struct {
+	l *Logger
+	// pre holds the encoded attributes of WithAttrs, the last open groups being left open.
+	pre  []byte
+	open int
+	// groups holds the groups opened since the last attributes.
+	groups []string
+}
+
+type slogRecord struct {
+	h  *SlogHandler
+	r  slog.Record
+	fn func(Entry)
+}
+
+var slogRecordPool = sync.Pool{
+	New: func() interface{} {
+		s := &slogRecord{}
+		s.fn = s.fields
+		return s
+	},
+}
+
+// NewSlogHandler returns a slog.Handler writing records with the logger l.
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{l: l}
+}
+
+// Enabled reports whether the logger has the onelog level matching the slog level enabled.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.levels&slogLevel(level) != 0
+}
+
+// Handle writes the record r.
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	s := slogRecordPool.Get().(*slogRecord)
+	s.h, s.r = h, r
+	switch slogLevel(r.Level) {
+	case DEBUG:
+		h.l.DebugWithFields(r.Message, s.fn)
+	case INFO:
+		h.l.InfoWithFields(r.Message, s.fn)
+	case WARN:
+		h.l.WarnWithFields(r.Message, s.fn)
+	default:
+		h.l.ErrorWithFields(r.Message, s.fn)
+	}
+	s.h, s.r = nil, slog.Record{}
+	slogRecordPool.Put(s)
+	return nil
+}
+
+// WithAttrs returns a handler adding the attributes attrs to each record.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	nonEmpty := false
+	for _, a := range attrs {
+		if !slogEmpty(a) {
+			nonEmpty = true
+			break
+		}
+	}
+	if !nonEmpty {
+		return h
+	}
+	enc := gojay.BorrowEncoder(nil)
+	defer enc.Release()
+	enc.AppendBytes(logOpen)
+	enc.AppendBytes(h.pre)
+	e := Entry{enc: enc, l: h.l}
+	for _, g := range h.groups {
+		e.key(g)
+		enc.AppendByte('{')
+	}
+	for _, a := range attrs {
+		e.slogAttr(a)
+	}
+	pre := make([]byte, len(enc.Buf())-len(logOpen))
+	copy(pre, enc.Buf()[len(logOpen):])
+	return &SlogHandler{l: h.l, pre: pre, open: h.open + len(h.groups)}
+}
+
+// WithGroup returns a handler nesting the attributes of records and of later calls to WithAttrs in the group name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{
+		l:      h.l,
+		pre:    h.pre,
+		open:   h.open,
+		groups: append(h.groups[:len(h.groups):len(h.groups)], name),
+	}
+}
+
+func (s *slogRecord) fields(e Entry) {
+	h, r := s.h, &s.r
+	if !r.Time.IsZero() {
+		e.Time(slog.TimeKey, r.Time, time.RFC3339Nano)
+	}
+	if len(h.pre) > 0 {
+		if b := e.enc.Buf(); b[len(b)-1] != '{' {
+			e.enc.AppendByte(',')
+		}
+		e.enc.AppendBytes(h.pre)
+	}
+	nonEmpty := false
+	r.Attrs(func(a slog.Attr) bool {
+		nonEmpty = !slogEmpty(a)
+		return !nonEmpty
+	})
+	if nonEmpty {
+		for _, g := range h.groups {
+			e.key(g)
+			e.enc.AppendByte('{')
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			e.slogAttr(a)
+			return true
+		})
+		for range h.groups {
+			e.enc.AppendByte('}')
+		}
+	}
+	for i := 0; i < h.open; i++ {
+		e.enc.AppendByte('}')
+	}
+}
+
+// slogAttr adds the attribute a to the log entry.
+func (e Entry) slogAttr(a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if slogEmpty(a) {
+		return
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		e.String(a.Key, a.Value.String())
+	case slog.KindInt64:
+		e.Int64(a.Key, a.Value.Int64())
+	case slog.KindUint64:
+		e.Uint64(a.Key, a.Value.Uint64())
+	case slog.KindFloat64:
+		e.Float(a.Key, a.Value.Float64())
+	case slog.KindBool:
+		e.Bool(a.Key, a.Value.Bool())
+	case slog.KindDuration:
+		e.Duration(a.Key, a.Value.Duration())
+	case slog.KindTime:
+		e.Time(a.Key, a.Value.Time(), time.RFC3339Nano)
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		// groups with an empty key are inlined.
+		if a.Key == "" {
+			for _, ga := range attrs {
+				e.slogAttr(ga)
+			}
+			return
+		}
+		if e.redact(a.Key) {
+			return
+		}
+		e.key(a.Key)
+		e.enc.AppendByte('{')
+		for _, ga := range attrs {
+			e.slogAttr(ga)
+		}
+		e.enc.AppendByte('}')
+	default:
+		e.Any(a.Key, a.Value.Any())
+	}
+}
+
+// slogEmpty reports whether the attribute a must be ignored: it is empty or a group without non empty attributes.
+func slogEmpty(a slog.Attr) bool {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		return a.Equal(slog.Attr{})
+	}
+	for _, ga := range a.Value.Group() {
+		if !slogEmpty(ga) {
+			return false
+		}
+	}
+	return true
+}
+
+// slogLevel returns the onelog level of the slog level l.
+func slogLevel(l slog.Level) uint8 {
+	switch {
+	case l < slog.LevelInfo:
+		return DEBUG
+	case l < slog.LevelWarn:
+		return INFO
+	case l < slog.LevelError:
+		return WARN
+	default:
+		return ERROR
+	}
+}
@@ -0,0 +1,93 @@
+package onelog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogHandler(t *testing.T) {
+	t.Run("slogtest", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := NewSlogHandler(New(w, ALL))
+		results := func() []map[string]interface{} {
+			var ms []map[string]interface{}
+			for _, line := range bytes.Split(w.Bytes(), []byte{'\n'}) {
+				if len(line) == 0 {
+					continue
+				}
+				var m map[string]interface{}
+				if err := json.Unmarshal(line, &m); err != nil {
+					t.Fatal(err)
+				}
+				// slogtest expects the message under slog's key.
+				m[slog.MessageKey] = m[msgKey]
+				delete(m, msgKey)
+				ms = append(ms, m)
+			}
+			return ms
+		}
+		if err := slogtest.TestHandler(h, results); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("same-output", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL)
+		slogger := slog.New(NewSlogHandler(logger))
+		logger.InfoWith("hello").String("foo", "bar").Int64("n", 1).Write()
+		r := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+		r.AddAttrs(slog.String("foo", "bar"), slog.Int("n", 1))
+		slogger.Handler().Handle(context.Background(), r)
+		lines := bytes.Split(w.Bytes(), []byte{'\n'})
+		assert.Equal(t, string(lines[0]), string(lines[1]), "slog and onelog entries should be identical")
+	})
+	t.Run("levels", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		h := NewSlogHandler(New(w, INFO|ERROR))
+		assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+		assert.True(t, h.Enabled(context.Background(), slog.LevelInfo))
+		assert.True(t, h.Enabled(context.Background(), slog.LevelInfo+2))
+		assert.False(t, h.Enabled(context.Background(), slog.LevelWarn))
+		assert.True(t, h.Enabled(context.Background(), slog.LevelError+4))
+		slogger := slog.New(h)
+		slogger.Debug("debug")
+		slogger.Info("info")
+		slogger.Warn("warn")
+		slogger.Log(context.Background(), slog.LevelError+4, "error")
+		assert.True(t, strings.HasPrefix(w.String(), `{"level":"info","message":"info","time":`))
+		assert.Contains(t, w.String(), `{"level":"error","message":"error","time":`)
+		assert.NotContains(t, w.String(), "debug")
+		assert.NotContains(t, w.String(), "warn")
+	})
+	t.Run("groups-and-attrs", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Redactor(NewRedactor().Keys("password")).Hook(func(e Entry) {
+			e.String("hook", "yes")
+		})
+		slogger := slog.New(NewSlogHandler(logger)).
+			With("a", 1).
+			WithGroup("g").
+			With("b", errors.New("boom"), "password", "secret").
+			WithGroup("h").
+			WithGroup("empty")
+		r := slog.NewRecord(time.Time{}, slog.LevelWarn, "hello", 0)
+		r.AddAttrs(
+			slog.Group("", slog.String("inlined", "yes")),
+			slog.Group("nested", slog.Duration("d", time.Second), slog.Any("strs", []string{"x"})),
+			slog.Group("empty"),
+		)
+		slogger.Handler().Handle(context.Background(), r)
+		json := `{"level":"warn","message":"hello","hook":"yes","a":1,"g":{"b":"boom","password":"[REDACTED]",` +
+			`"h":{"empty":{"inlined":"yes","nested":{"d":1000000000,"strs":["x"]}}}}}` + "\n"
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+	})
+}
@@ -0,0 +1,228 @@
+package onelog
+
+import (
+	"bytes"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// StdWriter is an io.Writer turning each line written into an entry of a Logger,
+// so libraries accepting only a *log.Logger, like net/http's Server.ErrorLog, log through onelog.
+// Lines are logged as they are written, each call to Write should pass whole lines as log.Logger does.
+type StdWriter struct {
+	l           *Logger
+	level       uint8
+	prefix      string
+	flags       int
+	parseFields bool
+	detectLevel bool
+}
+
+// stdLevels are the level prefixes detected by StdWriter.DetectLevel.
+var stdLevels = []struct {
+	prefix string
+	level  uint8
+}{
+	{"[DEBUG]", DEBUG},
+	{"[INFO]", INFO},
+	{"[WARN]", WARN},
+	{"[WARNING]", WARN},
+	{"[ERROR]", ERROR},
+	{"[ERR]", ERROR},
+}
+
+// NewStdWriter returns a StdWriter logging lines on l with the given level.
+// The level must be a single level, masks like INFO|WARN and unknown levels default to INFO.
+func NewStdWriter(l *Logger, level uint8) *StdWriter {
+	switch level {
+	case DEBUG, INFO, WARN, ERROR, FATAL:
+	default:
+		level = INFO
+	}
+	return &StdWriter{l: l, level: level}
+}
+
+// NewStdLogger returns a *log.Logger logging lines on l with the given level,
+// see NewStdWriter.
+func NewStdLogger(l *Logger, level uint8) *log.Logger {
+	return NewStdWriter(l, level).Logger()
+}
+
+// Logger returns a *log.Logger writing to w.
+func (w *StdWriter) Logger() *log.Logger {
+	return log.New(w, "", 0)
+}
+
+// Strip sets the prefix and flags of the *log.Logger writing to w so they are removed from lines,
+// it is only needed when w is passed to a *log.Logger not created with Logger.
+func (w *StdWriter) Strip(prefix string, flags int) *StdWriter {
+	w.prefix = prefix
+	w.flags = flags
+	return w
+}
+
+// ParseFields enables parsing key=value pairs of lines into string fields,
+// values can be quoted with double quotes. Pairs are removed from the message.
+func (w *StdWriter) ParseFields() *StdWriter {
+	w.parseFields = true
+	return w
+}
+
+// DetectLevel enables detecting level prefixes like "[ERROR]" or "[WARN]" at the start of lines
+// to choose the level of the entry. The prefix is removed from the message.
+func (w *StdWriter) DetectLevel() *StdWriter {
+	w.detectLevel = true
+	return w
+}
+
+// Write logs each non empty line of p.
+func (w *StdWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		var line []byte
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line, p = p[:i], p[i+1:]
+		} else {
+			line, p = p, nil
+		}
+		line = bytes.TrimRight(line, "\r")
+		if len(line) > 0 {
+			w.log(string(line))
+		}
+	}
+	return n, nil
+}
+
+func (w *StdWriter) log(line string) {
+	line = w.strip(line)
+	level := w.level
+	if w.detectLevel {
+		for _, l := range stdLevels {
+			if len(line) >= len(l.prefix) && strings.EqualFold(line[:len(l.prefix)], l.prefix) {
+				level = l.level
+				line = strings.TrimLeft(line[len(l.prefix):], " ")
+				break
+			}
+		}
+	}
+	var fields []string
+	if w.parseFields {
+		line, fields = parseKeyValues(line)
+	}
+	w.l.logFields(level, line, func(e Entry) {
+		for i := 0; i < len(fields); i += 2 {
+			e.String(fields[i], fields[i+1])
+		}
+	})
+}
+
+// strip removes the prefix and header written by a *log.Logger with w's prefix and flags.
+func (w *StdWriter) strip(line string) string {
+	if w.flags&log.Lmsgprefix == 0 {
+		line = strings.TrimPrefix(line, w.prefix)
+	}
+	if w.flags&log.Ldate != 0 && len(line) >= len("2009/01/23 ") {
+		line = line[len("2009/01/23 "):]
+	}
+	if w.flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		n := len("01:23:23 ")
+		if w.flags&log.Lmicroseconds != 0 {
+			n = len("01:23:23.123123 ")
+		}
+		if len(line) >= n {
+			line = line[n:]
+		}
+	}
+	if w.flags&(log.Lshortfile|log.Llongfile) != 0 {
+		if i := strings.Index(line, ": "); i >= 0 {
+			line = line[i+2:]
+		}
+	}
+	if w.flags&log.Lmsgprefix != 0 {
+		line = strings.TrimPrefix(line, w.prefix)
+	}
+	return line
+}
+
+// parseKeyValues returns the line without its key=value pairs and the pairs.
+func parseKeyValues(line string) (string, []string) {
+	var msg strings.Builder
+	var fields []string
+	for len(line) > 0 {
+		line = strings.TrimLeft(line, " ")
+		if line == "" {
+			break
+		}
+		var token string
+		token, line = nextToken(line)
+		if k, v, ok := keyValue(token); ok {
+			fields = append(fields, k, v)
+			continue
+		}
+		if msg.Len() > 0 {
+			msg.WriteByte(' ')
+		}
+		msg.WriteString(token)
+	}
+	return msg.String(), fields
+}
+
+// nextToken returns the first space separated token of s, a double quoted part may contain spaces.
+func nextToken(s string) (string, string) {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case ' ':
+			if !quoted {
+				return s[:i], s[i:]
+			}
+		}
+	}
+	return s, ""
+}
+
+// keyValue splits a key=value token, the value being unquoted if it is quoted.
+func keyValue(token string) (string, string, bool) {
+	i := strings.IndexByte(token, '=')
+	if i <= 0 {
+		return "", "", false
+	}
+	k, v := token[:i], token[i+1:]
+	for j := 0; j < len(k); j++ {
+		c := k[j]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-') {
+			return "", "", false
+		}
+	}
+	if len(v) > 0 && v[0] == '"' {
+		u, err := strconv.Unquote(v)
+		if err != nil {
+			return "", "", false
+		}
+		v = u
+	}
+	return k, v, true
+}
+
+// logFields logs an entry with the given level and fields.
+func (l *Logger) logFields(level uint8, msg string, fields func(Entry)) {
+	switch level {
+	case DEBUG:
+		l.DebugWithFields(msg, fields)
+	case INFO:
+		l.InfoWithFields(msg, fields)
+	case WARN:
+		l.WarnWithFields(msg, fields)
+	case ERROR:
+		l.ErrorWithFields(msg, fields)
+	case FATAL:
+		l.FatalWithFields(msg, fields)
+	}
+}
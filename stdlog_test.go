@@ -0,0 +1,88 @@
+package onelog
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdWriter(t *testing.T) {
+	t.Run("logger", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		stdLogger := NewStdLogger(New(w, ALL), WARN)
+		stdLogger.Println("http: TLS handshake error")
+		stdLogger.Printf("multi\nline")
+		json := `{"level":"warn","message":"http: TLS handshake error"}` + "\n" +
+			`{"level":"warn","message":"multi"}` + "\n" +
+			`{"level":"warn","message":"line"}` + "\n"
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("strip", func(t *testing.T) {
+		testCases := []struct {
+			name  string
+			flags int
+		}{
+			{"no-flags", 0},
+			{"std-flags", log.LstdFlags},
+			{"microseconds", log.Ldate | log.Lmicroseconds},
+			{"short-file", log.LstdFlags | log.Lshortfile},
+			{"long-file", log.Ltime | log.Llongfile},
+			{"msg-prefix", log.LstdFlags | log.Lshortfile | log.Lmsgprefix},
+		}
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				w := &bytes.Buffer{}
+				stdLogger := log.New(NewStdWriter(New(w, ALL), INFO).Strip("[db] ", testCase.flags), "[db] ", testCase.flags)
+				stdLogger.Print("connection: reset")
+				assert.Equal(t, `{"level":"info","message":"connection: reset"}`+"\n", w.String(), "bytes written to the writer dont equal expected result")
+			})
+		}
+	})
+	t.Run("detect-level", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		stdLogger := NewStdWriter(New(w, ALL), INFO).DetectLevel().Logger()
+		stdLogger.Print("[ERROR] failed")
+		stdLogger.Print("[warn]  slow")
+		stdLogger.Print("[WARNING] slower")
+		stdLogger.Print("[DEBUG] details")
+		stdLogger.Print("no level [ERROR]")
+		json := `{"level":"error","message":"failed"}` + "\n" +
+			`{"level":"warn","message":"slow"}` + "\n" +
+			`{"level":"warn","message":"slower"}` + "\n" +
+			`{"level":"debug","message":"details"}` + "\n" +
+			`{"level":"info","message":"no level [ERROR]"}` + "\n"
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("parse-fields", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		stdLogger := NewStdWriter(New(w, ALL), INFO).DetectLevel().ParseFields().Logger()
+		stdLogger.Print(`[ERROR] query failed db=users took=12ms err="context deadline exceeded" a==b =c`)
+		json := `{"level":"error","message":"query failed =c","db":"users","took":"12ms","err":"context deadline exceeded","a":"=b"}` + "\n"
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("disabled-level", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		NewStdLogger(New(w, ERROR), INFO).Print("hello")
+		assert.Equal(t, ``, w.String(), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("invalid-level", func(t *testing.T) {
+		testCases := []struct {
+			name  string
+			level uint8
+		}{
+			{"mask", INFO | WARN},
+			{"all", ALL},
+			{"zero", 0},
+			{"unknown", 0x20},
+		}
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				w := &bytes.Buffer{}
+				NewStdLogger(New(w, ALL), testCase.level).Print("hello")
+				assert.Equal(t, `{"level":"info","message":"hello"}`+"\n", w.String(), "lines should be logged at INFO level")
+			})
+		}
+	})
+}
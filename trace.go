@@ -0,0 +1,110 @@
+package onelog
+
+import (
+	"context"
+	"encoding/hex"
+)
+
+// SpanContext identifies a span of a W3C trace context.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+}
+
+// IsValid reports whether the trace ID and the span ID are non zero.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceSource returns the span context active in a context.
+// Implement it to get the span context from a tracing SDK, like OpenTelemetry:
+//
+//	type otelSource struct{}
+//
+//	func (otelSource) SpanContext(ctx context.Context) (onelog.SpanContext, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return onelog.SpanContext{TraceID: sc.TraceID(), SpanID: sc.SpanID(), TraceFlags: byte(sc.TraceFlags())}, sc.IsValid()
+//	}
+type TraceSource interface {
+	SpanContext(ctx context.Context) (SpanContext, bool)
+}
+
+// TraceSourceFunc is a function implementing TraceSource.
+type TraceSourceFunc func(ctx context.Context) (SpanContext, bool)
+
+// SpanContext calls f.
+func (f TraceSourceFunc) SpanContext(ctx context.Context) (SpanContext, bool) {
+	return f(ctx)
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx holding the span context sc, read by TraceContext.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// ContextWithTraceparent returns a copy of ctx holding the span context parsed from a traceparent header value,
+// or ctx if the value is invalid. It is meant for services without a tracing SDK.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	sc, ok := ParseTraceparent(traceparent)
+	if !ok {
+		return ctx
+	}
+	return ContextWithSpanContext(ctx, sc)
+}
+
+// ParseTraceparent parses a W3C traceparent header value like "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func ParseTraceparent(s string) (SpanContext, bool) {
+	var sc SpanContext
+	// version-trace_id-parent_id-flags, future versions may append fields after a dash.
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' || (len(s) > 55 && s[55] != '-') {
+		return sc, false
+	}
+	var version [1]byte
+	if !decodeHex(version[:], s[:2]) || version[0] == 0xff || (version[0] == 0 && len(s) != 55) {
+		return sc, false
+	}
+	var flags [1]byte
+	if !decodeHex(sc.TraceID[:], s[3:35]) || !decodeHex(sc.SpanID[:], s[36:52]) || !decodeHex(flags[:], s[53:55]) {
+		return sc, false
+	}
+	sc.TraceFlags = flags[0]
+	return sc, sc.IsValid()
+}
+
+// decodeHex decodes the lowercase hex string s into dst.
+func decodeHex(dst []byte, s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= 'A' && c <= 'F' {
+			return false
+		}
+	}
+	n, err := hex.Decode(dst, []byte(s))
+	return err == nil && n == len(dst)
+}
+
+// TraceContext returns an extractor adding the "trace_id", "span_id" and "trace_flags" fields
+// of the OpenTelemetry log data model, as lowercase hex strings.
+// The span context is read from the first source returning one,
+// or from the span context held by the context with ContextWithSpanContext or ContextWithTraceparent.
+func TraceContext(sources ...TraceSource) Extractor {
+	return func(ctx context.Context, e Entry) {
+		for _, s := range sources {
+			if sc, ok := s.SpanContext(ctx); ok && sc.IsValid() {
+				e.spanContext(sc)
+				return
+			}
+		}
+		if sc, ok := ctx.Value(spanContextKey{}).(SpanContext); ok && sc.IsValid() {
+			e.spanContext(sc)
+		}
+	}
+}
+
+func (e Entry) spanContext(sc SpanContext) {
+	e.Hex("trace_id", sc.TraceID[:])
+	e.Hex("span_id", sc.SpanID[:])
+	e.Hex("trace_flags", []byte{sc.TraceFlags})
+}
@@ -0,0 +1,76 @@
+package onelog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"valid", testTraceparent, true},
+		{"future-version", "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what-the-future-will-be", true},
+		{"future-version-bad-separator", "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01x", false},
+		{"version-00-too-long", testTraceparent + "-00", false},
+		{"invalid-version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"zero-trace-id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"zero-span-id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"not-hex", "00-4bf92f3577b34da6a3ce929d0e0e473g-00f067aa0ba902b7-01", false},
+		{"too-short", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", false},
+		{"empty", "", false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, ok := ParseTraceparent(testCase.value)
+			assert.Equal(t, testCase.valid, ok)
+		})
+	}
+	sc, _ := ParseTraceparent(testTraceparent)
+	assert.Equal(t, SpanContext{
+		TraceID:    [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: 1,
+	}, sc)
+}
+
+func TestTraceContext(t *testing.T) {
+	json := `{"level":"info","message":"hello","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_flags":"01"}` + "\n"
+	t.Run("traceparent", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		logger := New(w, ALL).Extractors(TraceContext())
+		ctx := ContextWithTraceparent(context.Background(), testTraceparent)
+		logger.Ctx(ctx).Info("hello")
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("source", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		sc, _ := ParseTraceparent(testTraceparent)
+		source := TraceSourceFunc(func(ctx context.Context) (SpanContext, bool) {
+			return sc, true
+		})
+		logger := New(w, ALL).Extractors(TraceContext(source))
+		// the source takes precedence over the span context held by the context.
+		ctx := ContextWithTraceparent(context.Background(), "00-11111111111111111111111111111111-2222222222222222-00")
+		logger.Ctx(ctx).Info("hello")
+		assert.Equal(t, json, w.String(), "bytes written to the writer dont equal expected result")
+	})
+	t.Run("no-span", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		source := TraceSourceFunc(func(ctx context.Context) (SpanContext, bool) {
+			return SpanContext{}, false
+		})
+		logger := New(w, ALL).Extractors(TraceContext(source))
+		ctx := ContextWithTraceparent(context.Background(), "invalid")
+		logger.Ctx(ctx).Info("hello")
+		assert.Equal(t, `{"level":"info","message":"hello"}`+"\n", w.String(), "bytes written to the writer dont equal expected result")
+	})
+}